@@ -3,51 +3,66 @@ package cerr
 import (
 	"connectrpc.com/connect"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/protodetail"
 )
 
-// RegisterCode registers a custom mapping between an errx.Code and a connect.Code.
-// Both forward (errx → Connect) and reverse (Connect → errx) mappings are registered.
+// RegisterCode registers a custom mapping between an errx.Code and a connect.Code
+// in the default [Mapper]. See [Mapper.Register].
+// Prefer [errx.RegisterCode], which declares the mappings of every transport at once.
 // Must be called at program initialization (e.g. in init()), before serving requests.
 func RegisterCode(c errx.Code, cc connect.Code) {
-	errxToConnect[c] = cc
-	connectToErrx[cc] = c
+	defaultMapper.Register(c, cc)
 }
 
-// ToConnectCode maps an errx.Code to a connect.Code.
-// Unknown or user-defined codes map to connect.CodeUnknown.
+// ToConnectCode maps an errx.Code to a connect.Code using the default [Mapper].
+// See [Mapper.ToConnectCode].
 func ToConnectCode(c errx.Code) connect.Code {
-	if cc, ok := errxToConnect[c]; ok {
-		return cc
-	}
-	return connect.CodeUnknown
+	return defaultMapper.ToConnectCode(c)
 }
 
-// ToErrxCode maps a connect.Code to an errx.Code.
-// Zero value (no error) maps to the zero value ("").
+// ToErrxCode maps a connect.Code to an errx.Code using the default [Mapper].
+// See [Mapper.ToErrxCode].
 func ToErrxCode(c connect.Code) errx.Code {
-	if ec, ok := connectToErrx[c]; ok {
-		return ec
-	}
-	return errx.Unknown
+	return defaultMapper.ToErrxCode(c)
 }
 
-// ToConnectError converts an error to a *connect.Error.
-// If the error carries an errx.Code, it is mapped to a Connect code.
-// Any detail objects (proto.Message) attached via errx.WithDetails are
-// included as Connect error details. Non-proto.Message details are ignored.
+// ToConnectError converts an error to a *connect.Error using the default [Mapper].
+// See [Mapper.ToConnectError].
 func ToConnectError(err error) *connect.Error {
+	return defaultMapper.ToConnectError(err)
+}
+
+// FromConnectError converts a *connect.Error to an *errx.Error using the default [Mapper].
+// See [Mapper.FromConnectError].
+func FromConnectError(err *connect.Error) *errx.Error {
+	return defaultMapper.FromConnectError(err)
+}
+
+// ToConnectError converts an error to a *connect.Error.
+// If the error carries an errx.Code, it is mapped to a Connect code by m.
+// The public message (see errx.PublicMessage) is used as the error message;
+// the original error remains reachable through Unwrap.
+// Detail objects attached via errx.WithDetails (plus a RetryInfo for the hint set
+// with errx.WithRetryAfter, see protodetail.DetailsOf) are converted with protodetail.ToProto
+// and included as Connect error details. Details no converter recognizes are dropped
+// and reported via errx.ReportUnknownDetail.
+func (m *Mapper) ToConnectError(err error) *connect.Error {
 	if err == nil {
 		return nil
 	}
 	c := errx.CodeOf(err)
-	ce := connect.NewError(ToConnectCode(c), err)
+	var cause error = err
+	if msg := errx.PublicMessage(err); msg != err.Error() {
+		cause = &publicError{msg: msg, err: err}
+	}
+	ce := connect.NewError(m.ToConnectCode(c), cause)
 
-	for _, d := range errx.DetailsOf(err) {
-		pm := toProtoDetail(d)
+	for _, d := range protodetail.DetailsOf(err) {
+		pm := protodetail.ToProto(d)
 		if pm == nil {
+			errx.ReportUnknownDetail("cerr", d)
 			continue
 		}
 		detail, detailErr := connect.NewErrorDetail(pm)
@@ -59,21 +74,27 @@ func ToConnectError(err error) *connect.Error {
 	return ce
 }
 
-// FromConnectError converts a *connect.Error to an *errx.Error.
+// FromConnectError converts a *connect.Error to an *errx.Error, mapping its code with m.
 // Returns nil if err is nil.
-// Any Connect error details are restored via errx.WithDetails.
-func FromConnectError(err *connect.Error) *errx.Error {
+// Any Connect error details are restored via errx.WithDetails; messages with a
+// FromProto converter registered in errx are converted back to their detail type,
+// so google.rpc error details come back as the built-in errx details (e.g. *errx.BadRequestDetail).
+// A RetryInfo detail also restores the retry-after hint (see errx.RetryAfterOf).
+func (m *Mapper) FromConnectError(err *connect.Error) *errx.Error {
 	if err == nil {
 		return nil
 	}
-	ex := errx.New(err.Message()).WithCode(ToErrxCode(err.Code()))
+	ex := errx.New(err.Message()).WithCode(m.ToErrxCode(err.Code()))
 	var details []any
 	for _, d := range err.Details() {
 		v, valErr := d.Value()
 		if valErr != nil {
 			continue
 		}
-		details = append(details, v)
+		if ri, ok := v.(*errdetails.RetryInfo); ok && ri.GetRetryDelay().AsDuration() > 0 {
+			ex = ex.WithRetryAfter(ri.GetRetryDelay().AsDuration())
+		}
+		details = append(details, protodetail.FromProto(v))
 	}
 	if len(details) > 0 {
 		ex = ex.WithDetails(details...)
@@ -81,6 +102,8 @@ func FromConnectError(err *connect.Error) *errx.Error {
 	return ex
 }
 
+// errxToConnect and connectToErrx hold the built-in mappings. They are never modified;
+// custom mappings are registered in a [Mapper].
 var errxToConnect = map[errx.Code]connect.Code{
 	errx.Canceled:           connect.CodeCanceled,
 	errx.Unknown:            connect.CodeUnknown,
@@ -100,51 +123,6 @@ var errxToConnect = map[errx.Code]connect.Code{
 	errx.Unauthenticated:    connect.CodeUnauthenticated,
 }
 
-// toProtoDetail converts an errx detail type to a proto.Message.
-// If the detail is already a proto.Message, it is returned as-is.
-// Returns nil for unrecognized types.
-func toProtoDetail(d any) proto.Message {
-	switch v := d.(type) {
-	case *errx.BadRequestDetail:
-		violations := make([]*errdetails.BadRequest_FieldViolation, len(v.Violations))
-		for i, fv := range v.Violations {
-			violations[i] = &errdetails.BadRequest_FieldViolation{
-				Field:       fv.Field,
-				Description: fv.Description,
-			}
-		}
-		return &errdetails.BadRequest{FieldViolations: violations}
-	case *errx.PreconditionFailureDetail:
-		violations := make([]*errdetails.PreconditionFailure_Violation, len(v.Violations))
-		for i, pv := range v.Violations {
-			violations[i] = &errdetails.PreconditionFailure_Violation{
-				Type:        pv.Type,
-				Subject:     pv.Subject,
-				Description: pv.Description,
-			}
-		}
-		return &errdetails.PreconditionFailure{Violations: violations}
-	case *errx.ResourceInfoDetail:
-		return &errdetails.ResourceInfo{
-			ResourceType: v.ResourceType,
-			ResourceName: v.ResourceName,
-			Owner:        v.Owner,
-			Description:  v.Description,
-		}
-	case *errx.ErrorInfoDetail:
-		return &errdetails.ErrorInfo{
-			Reason:   v.Reason,
-			Domain:   v.Domain,
-			Metadata: v.Metadata,
-		}
-	default:
-		if pm, ok := d.(proto.Message); ok {
-			return pm
-		}
-		return nil
-	}
-}
-
 var connectToErrx = map[connect.Code]errx.Code{
 	0:                              "",
 	connect.CodeCanceled:           errx.Canceled,
@@ -164,3 +142,14 @@ var connectToErrx = map[connect.Code]errx.Code{
 	connect.CodeDataLoss:           errx.DataLoss,
 	connect.CodeUnauthenticated:    errx.Unauthenticated,
 }
+
+// publicError carries the public message of an error to connect.NewError,
+// which uses Error() as the wire message, while keeping the original error
+// reachable through Unwrap.
+type publicError struct {
+	msg string
+	err error
+}
+
+func (e *publicError) Error() string { return e.msg }
+func (e *publicError) Unwrap() error { return e.err }
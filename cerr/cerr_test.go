@@ -4,9 +4,11 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/mickamy/errx"
 	"github.com/mickamy/errx/cerr"
@@ -15,8 +17,35 @@ import (
 const testCustomCode errx.Code = "payment_required"
 const testCustomConnect connect.Code = 100
 
+// testDeclaredCode is mapped through the errx code registry rather than RegisterCode.
+const testDeclaredCode errx.Code = "legal/blocked"
+
+// planDetail is a custom detail type registered with errx for tests.
+type planDetail struct {
+	Plan string `json:"plan"`
+}
+
+func registerPlanDetail() {
+	errx.RegisterDetail(errx.DetailType{
+		Name: "Plan",
+		New:  func() any { return &planDetail{} },
+		ToProto: func(d any) any {
+			return wrapperspb.String(d.(*planDetail).Plan) //nolint:forcetypeassert // registered type
+		},
+		FromProto: func(m any) (any, bool) {
+			sv, ok := m.(*wrapperspb.StringValue)
+			if !ok {
+				return nil, false
+			}
+			return &planDetail{Plan: sv.GetValue()}, true
+		},
+	})
+}
+
 func TestMain(m *testing.M) {
+	errx.RegisterCode(errx.CodeInfo{Code: testDeclaredCode, ConnectCode: 101})
 	cerr.RegisterCode(testCustomCode, testCustomConnect)
+	registerPlanDetail()
 	os.Exit(m.Run())
 }
 
@@ -31,6 +60,20 @@ func TestRegisterCode(t *testing.T) {
 	}
 }
 
+func TestDeclaredCode(t *testing.T) {
+	t.Parallel()
+
+	if got := cerr.ToConnectCode(testDeclaredCode); got != connect.Code(101) {
+		t.Errorf("ToConnectCode(%q) = %v, want %v", testDeclaredCode, got, connect.Code(101))
+	}
+	if got := cerr.ToConnectCode(testDeclaredCode + ".region"); got != connect.Code(101) {
+		t.Errorf("ToConnectCode(%q) = %v, want %v", testDeclaredCode+".region", got, connect.Code(101))
+	}
+	if got := cerr.ToErrxCode(connect.Code(101)); got != testDeclaredCode {
+		t.Errorf("ToErrxCode(%v) = %q, want %q", connect.Code(101), got, testDeclaredCode)
+	}
+}
+
 func TestToConnectCode(t *testing.T) {
 	t.Parallel()
 
@@ -47,6 +90,8 @@ func TestToConnectCode(t *testing.T) {
 		{errx.PermissionDenied, connect.CodePermissionDenied},
 		{errx.Unauthenticated, connect.CodeUnauthenticated},
 		{errx.Unavailable, connect.CodeUnavailable},
+		{errx.Code("not_found.user"), connect.CodeNotFound},
+		{errx.Code("unavailable.db.primary"), connect.CodeUnavailable},
 		{errx.Code("custom"), connect.CodeUnknown},
 		{errx.Code(""), connect.CodeUnknown},
 	}
@@ -138,6 +183,21 @@ func TestToConnectError(t *testing.T) {
 			t.Errorf("details length = %d, want 0", len(ce.Details()))
 		}
 	})
+
+	t.Run("public message", func(t *testing.T) {
+		t.Parallel()
+		dbErr := errors.New("pq: connection refused")
+		err := errx.Wrap(dbErr).
+			WithCode(errx.Unavailable).
+			WithPublicMessage("service unavailable")
+		ce := cerr.ToConnectError(err)
+		if ce.Message() != "service unavailable" {
+			t.Errorf("message = %q, want %q", ce.Message(), "service unavailable")
+		}
+		if !errors.Is(ce, dbErr) {
+			t.Error("the original error should remain reachable")
+		}
+	})
 }
 
 func TestFromConnectError(t *testing.T) {
@@ -184,12 +244,12 @@ func TestFromConnectError(t *testing.T) {
 		if len(details) != 1 {
 			t.Fatalf("details length = %d, want 1", len(details))
 		}
-		got, ok := details[0].(*errdetails.BadRequest)
+		got, ok := details[0].(*errx.BadRequestDetail)
 		if !ok {
-			t.Fatalf("detail type = %T, want *errdetails.BadRequest", details[0])
+			t.Fatalf("detail type = %T, want *errx.BadRequestDetail", details[0])
 		}
-		if got.GetFieldViolations()[0].GetField() != "email" {
-			t.Errorf("field = %q, want %q", got.GetFieldViolations()[0].GetField(), "email")
+		if got.Violations[0].Field != "email" {
+			t.Errorf("field = %q, want %q", got.Violations[0].Field, "email")
 		}
 	})
 }
@@ -219,6 +279,21 @@ func TestToConnectError_WithErrxDetails(t *testing.T) {
 		}
 	})
 
+	t.Run("details from every joined branch", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Join(
+			errx.New("name").WithCode(errx.InvalidArgument).WithFieldViolation("name", "required"),
+			errx.New("email").WithCode(errx.InvalidArgument).WithFieldViolation("email", "invalid"),
+		)
+		ce := cerr.ToConnectError(err)
+		if ce.Code() != connect.CodeInvalidArgument {
+			t.Errorf("code = %v, want InvalidArgument", ce.Code())
+		}
+		if len(ce.Details()) != 2 {
+			t.Fatalf("details length = %d, want 2", len(ce.Details()))
+		}
+	})
+
 	t.Run("PreconditionFailureDetail", func(t *testing.T) {
 		t.Parallel()
 		err := errx.New("precondition failed").
@@ -325,11 +400,63 @@ func TestRoundTrip(t *testing.T) {
 	if len(details) != 1 {
 		t.Fatalf("details length = %d, want 1", len(details))
 	}
-	got, ok := details[0].(*errdetails.BadRequest)
+	got, ok := details[0].(*errx.BadRequestDetail)
 	if !ok {
-		t.Fatalf("detail type = %T, want *errdetails.BadRequest", details[0])
+		t.Fatalf("detail type = %T, want *errx.BadRequestDetail", details[0])
+	}
+	if got.Violations[0].Field != "name" {
+		t.Errorf("field = %q, want %q", got.Violations[0].Field, "name")
 	}
-	if got.GetFieldViolations()[0].GetField() != "name" {
-		t.Errorf("field = %q, want %q", got.GetFieldViolations()[0].GetField(), "name")
+}
+
+func TestRoundTrip_RegisteredDetail(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("upgrade needed").
+		WithCode(errx.FailedPrecondition).
+		WithDetails(&planDetail{Plan: "pro"})
+
+	ce := cerr.ToConnectError(original)
+	if len(ce.Details()) != 1 {
+		t.Fatalf("details length = %d, want 1", len(ce.Details()))
+	}
+
+	details := errx.DetailsOf(cerr.FromConnectError(ce))
+	if len(details) != 1 {
+		t.Fatalf("details length = %d, want 1", len(details))
+	}
+	if pd, ok := details[0].(*planDetail); !ok || pd.Plan != "pro" {
+		t.Errorf("detail = %#v, want &planDetail{Plan: pro}", details[0])
+	}
+}
+
+func TestToConnectError_ReportsUnknownDetail(t *testing.T) { //nolint:paralleltest // installs a global handler
+	var reported []any
+	errx.SetUnknownDetailHandler(func(transport string, detail any) {
+		if transport == "cerr" {
+			reported = append(reported, detail)
+		}
+	})
+	defer errx.SetUnknownDetailHandler(nil)
+
+	cerr.ToConnectError(errx.New("fail").WithDetails("opaque", errx.FieldViolation("a", "b")))
+	if len(reported) != 1 || reported[0] != "opaque" {
+		t.Errorf("reported = %v, want [opaque]", reported)
+	}
+}
+
+func TestRoundTrip_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("busy").WithCode(errx.ResourceExhausted).WithRetryAfter(3 * time.Second)
+
+	ce := cerr.ToConnectError(original)
+	if len(ce.Details()) != 1 {
+		t.Fatalf("details length = %d, want 1", len(ce.Details()))
+	}
+
+	recovered := cerr.FromConnectError(ce)
+	if d, ok := errx.RetryAfterOf(recovered); !ok || d != 3*time.Second {
+		t.Errorf("RetryAfterOf() = %v, %v, want 3s, true", d, ok)
 	}
 }
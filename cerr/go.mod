@@ -4,7 +4,8 @@ go 1.25.0
 
 require (
 	connectrpc.com/connect v1.19.1
-	github.com/mickamy/errx v0.0.5
+	github.com/mickamy/errx v0.0.6
+	github.com/mickamy/errx/protodetail v0.0.6
 	golang.org/x/text v0.34.0
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260217215200-42d3e9bedb6d
 	google.golang.org/protobuf v1.36.11
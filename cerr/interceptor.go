@@ -3,11 +3,11 @@ package cerr
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"connectrpc.com/connect"
 	"golang.org/x/text/language"
-	"google.golang.org/genproto/googleapis/rpc/errdetails"
 
 	"github.com/mickamy/errx"
 )
@@ -18,6 +18,9 @@ type InterceptorOption func(*interceptorConfig)
 type interceptorConfig struct {
 	localeFunc    func(http.Header) string
 	defaultLocale language.Tag
+	recovery      bool
+	mapper        *Mapper
+	logger        *slog.Logger
 }
 
 // WithLocaleFunc sets a custom function to extract locale from request headers.
@@ -40,9 +43,37 @@ func WithDefaultLocale(tag language.Tag) InterceptorOption {
 	}
 }
 
+// WithRecovery makes the interceptor recover panics in handlers. The panic value
+// is converted with errx.FromPanic (code Internal, stack at the panic site) and
+// returned to the client like any other error.
+func WithRecovery() InterceptorOption {
+	return func(cfg *interceptorConfig) {
+		cfg.recovery = true
+	}
+}
+
+// WithLogger makes the interceptor log every error a handler returns (or panic it
+// recovers) with errx.Log, at the level of errx.LevelOf, together with the procedure name.
+func WithLogger(l *slog.Logger) InterceptorOption {
+	return func(cfg *interceptorConfig) {
+		cfg.logger = l
+	}
+}
+
+// WithMapper sets the [Mapper] used to convert errors. The default is [DefaultMapper].
+func WithMapper(m *Mapper) InterceptorOption {
+	return func(cfg *interceptorConfig) {
+		if m == nil {
+			return
+		}
+		cfg.mapper = m
+	}
+}
+
 func newInterceptorConfig(opts []InterceptorOption) *interceptorConfig {
 	cfg := &interceptorConfig{
 		localeFunc: defaultLocaleFunc,
+		mapper:     defaultMapper,
 	}
 	for _, o := range opts {
 		o(cfg)
@@ -55,7 +86,7 @@ func defaultLocaleFunc(h http.Header) string {
 }
 
 // NewInterceptor returns a Connect interceptor that converts returned errors
-// to Connect errors using ToConnectError.
+// to Connect errors using [Mapper.ToConnectError].
 // If the error implements errx.Localizable, a LocalizedMessage detail
 // is automatically appended based on the request's Accept-Language header.
 func NewInterceptor(opts ...InterceptorOption) connect.Interceptor {
@@ -70,10 +101,17 @@ type interceptor struct {
 }
 
 func (i *interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
-	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
-		resp, err := next(ctx, req)
+	return func(ctx context.Context, req connect.AnyRequest) (resp connect.AnyResponse, err error) {
+		if i.cfg.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					resp, err = nil, i.cfg.toConnectError(ctx, req.Spec(), req.Header(), errx.FromPanic(r))
+				}
+			}()
+		}
+		resp, err = next(ctx, req)
 		if err != nil {
-			return nil, i.cfg.toConnectError(req.Header(), err)
+			return nil, i.cfg.toConnectError(ctx, req.Spec(), req.Header(), err)
 		}
 		return resp, nil
 	}
@@ -84,18 +122,32 @@ func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) conn
 }
 
 func (i *interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
-	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
-		err := next(ctx, conn)
-		if err != nil {
-			return i.cfg.toConnectError(conn.RequestHeader(), err)
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) (err error) {
+		if i.cfg.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					err = i.cfg.toConnectError(ctx, conn.Spec(), conn.RequestHeader(), errx.FromPanic(r))
+				}
+			}()
+		}
+		if err = next(ctx, conn); err != nil {
+			return i.cfg.toConnectError(ctx, conn.Spec(), conn.RequestHeader(), err)
 		}
 		return nil
 	}
 }
 
-func (cfg *interceptorConfig) toConnectError(header http.Header, err error) error {
+// toConnectError converts an error to a Connect error, automatically appending
+// a LocalizedMessage detail if the error implements errx.Localizable.
+// The error is logged first if a logger is configured.
+func (cfg *interceptorConfig) toConnectError(
+	ctx context.Context, spec connect.Spec, header http.Header, err error,
+) error {
+	if cfg.logger != nil {
+		errx.Log(ctx, cfg.logger, err, "rpc failed", "procedure", spec.Procedure)
+	}
 	err = appendLocalizedDetail(header, err, cfg.localeFunc, cfg.defaultLocale)
-	return ToConnectError(err)
+	return cfg.mapper.ToConnectError(err)
 }
 
 func appendLocalizedDetail(
@@ -118,7 +170,7 @@ func appendLocalizedDetail(
 	}
 	var ex *errx.Error
 	if errors.As(err, &ex) {
-		return ex.WithDetails(&errdetails.LocalizedMessage{Locale: locale, Message: msg})
+		return ex.WithDetails(errx.LocalizedMessage(locale, msg))
 	}
-	return errx.Wrap(err).WithDetails(&errdetails.LocalizedMessage{Locale: locale, Message: msg})
+	return errx.Wrap(err).WithDetails(errx.LocalizedMessage(locale, msg))
 }
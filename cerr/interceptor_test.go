@@ -320,3 +320,48 @@ func TestNewInterceptor_StreamingClient_PassThrough(t *testing.T) {
 		t.Error("StreamingClient should pass through")
 	}
 }
+
+func TestNewInterceptor_WithRecovery(t *testing.T) {
+	t.Parallel()
+
+	i := cerr.NewInterceptor(cerr.WithRecovery())
+
+	t.Run("unary", func(t *testing.T) {
+		t.Parallel()
+		inner := i.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+			panic("boom")
+		})
+		resp, err := inner(t.Context(), newTestRequest(http.Header{}))
+		if resp != nil {
+			t.Errorf("resp should be nil, got %v", resp)
+		}
+		var ce *connect.Error
+		if !errors.As(err, &ce) {
+			t.Fatal("error should be a *connect.Error")
+		}
+		if ce.Code() != connect.CodeInternal {
+			t.Errorf("code = %v, want Internal", ce.Code())
+		}
+		if ce.Message() != "internal error" {
+			t.Errorf("message = %q, want %q (the panic value must not be sent)", ce.Message(), "internal error")
+		}
+	})
+
+	t.Run("streaming handler", func(t *testing.T) {
+		t.Parallel()
+		wrapped := i.WrapStreamingHandler(func(_ context.Context, _ connect.StreamingHandlerConn) error {
+			panic(errors.New("boom"))
+		})
+		err := wrapped(t.Context(), &fakeStreamingHandlerConn{header: http.Header{}})
+		var ce *connect.Error
+		if !errors.As(err, &ce) {
+			t.Fatal("error should be a *connect.Error")
+		}
+		if ce.Code() != connect.CodeInternal {
+			t.Errorf("code = %v, want Internal", ce.Code())
+		}
+		if ce.Message() != "internal error" {
+			t.Errorf("message = %q, want %q (the panic value must not be sent)", ce.Message(), "internal error")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package cerr
+
+import (
+	"sync"
+
+	"connectrpc.com/connect"
+
+	"github.com/mickamy/errx"
+)
+
+// Mapper maps errx codes to Connect codes and back.
+// It starts from the built-in mappings and those declared with [errx.RegisterCode];
+// [Mapper.Register] adds custom mappings that only this Mapper uses, so that servers
+// in one binary (e.g. a public and an internal API) can map codes differently.
+// The zero value is ready to use. A Mapper is safe for concurrent use
+// and must not be copied after first use.
+type Mapper struct {
+	mu        sync.RWMutex
+	toConnect map[errx.Code]connect.Code
+	toErrx    map[connect.Code]errx.Code
+}
+
+// defaultMapper is used by the package-level functions and by the interceptor without [WithMapper].
+var defaultMapper = NewMapper()
+
+// NewMapper returns a Mapper with only the built-in and registry mappings.
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// DefaultMapper returns the Mapper used by the package-level functions
+// and by the interceptor without [WithMapper].
+func DefaultMapper() *Mapper {
+	return defaultMapper
+}
+
+// Register registers a custom mapping between an errx.Code and a connect.Code.
+// Both forward (errx → Connect) and reverse (Connect → errx) mappings are registered,
+// overriding the built-in ones.
+// It panics if c is malformed (see [errx.ValidateCode]).
+func (m *Mapper) Register(c errx.Code, cc connect.Code) {
+	if err := errx.ValidateCode(c); err != nil {
+		panic(err.Error())
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.toConnect == nil {
+		m.toConnect = map[errx.Code]connect.Code{}
+		m.toErrx = map[connect.Code]errx.Code{}
+	}
+	m.toConnect[c] = cc
+	m.toErrx[cc] = c
+}
+
+// ToConnectCode maps an errx.Code to a connect.Code.
+// A code is mapped by [Mapper.Register], else by the ConnectCode of its [errx.CodeInfo]
+// (which may override a built-in mapping), else by the built-in mappings.
+// A code without a mapping of its own maps like its nearest mapped ancestor
+// (see [errx.Code.Parent]); codes with none map to connect.CodeUnknown.
+func (m *Mapper) ToConnectCode(c errx.Code) connect.Code {
+	for ; c != ""; c = c.Parent() {
+		m.mu.RLock()
+		cc, ok := m.toConnect[c]
+		m.mu.RUnlock()
+		if ok {
+			return cc
+		}
+		if info, ok := errx.LookupCode(c); ok && info.ConnectCode != 0 {
+			return connect.Code(info.ConnectCode)
+		}
+		if cc, ok := errxToConnect[c]; ok {
+			return cc
+		}
+	}
+	return connect.CodeUnknown
+}
+
+// ToErrxCode maps a connect.Code to an errx.Code.
+// Zero value (no error) maps to the zero value ("").
+// Codes declared with a matching ConnectCode in [errx.CodeInfo] are used for values
+// without a registered or built-in mapping; the rest map to errx.Unknown.
+func (m *Mapper) ToErrxCode(cc connect.Code) errx.Code {
+	m.mu.RLock()
+	c, ok := m.toErrx[cc]
+	m.mu.RUnlock()
+	if ok {
+		return c
+	}
+	if c, ok := connectToErrx[cc]; ok {
+		return c
+	}
+	for _, info := range errx.Codes() {
+		if info.ConnectCode == uint32(cc) {
+			return info.Code
+		}
+	}
+	return errx.Unknown
+}
@@ -0,0 +1,111 @@
+package cerr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/cerr"
+)
+
+const testAdminCode errx.Code = "admin/locked"
+
+func TestToConnectCode_RegistryOverridesBuiltin(t *testing.T) { //nolint:paralleltest // declares a global code mapping
+	orig, _ := errx.LookupCode(errx.NotFound)
+	errx.RegisterCode(errx.CodeInfo{Code: errx.NotFound, ConnectCode: uint32(connect.CodeUnavailable)})
+	t.Cleanup(func() {
+		orig.ConnectCode = uint32(connect.CodeNotFound)
+		errx.RegisterCode(orig)
+	})
+
+	if got := cerr.ToConnectCode(errx.NotFound); got != connect.CodeUnavailable {
+		t.Errorf("ToConnectCode(%q) = %v, want %v", errx.NotFound, got, connect.CodeUnavailable)
+	}
+	if got := cerr.ToConnectCode("not_found.user"); got != connect.CodeUnavailable {
+		t.Errorf("ToConnectCode(%q) = %v, want %v", "not_found.user", got, connect.CodeUnavailable)
+	}
+}
+
+func TestMapper_Register(t *testing.T) {
+	t.Parallel()
+
+	public := cerr.NewMapper()
+	admin := cerr.NewMapper()
+	admin.Register(testAdminCode, connect.CodeFailedPrecondition)
+	admin.Register("not_found.user", connect.CodePermissionDenied)
+
+	tests := []struct {
+		name   string
+		mapper *cerr.Mapper
+		code   errx.Code
+		want   connect.Code
+	}{
+		{"registered", admin, testAdminCode, connect.CodeFailedPrecondition},
+		{"registered child", admin, testAdminCode + ".account", connect.CodeFailedPrecondition},
+		{"overrides parent", admin, "not_found.user", connect.CodePermissionDenied},
+		{"built-in", admin, errx.NotFound, connect.CodeNotFound},
+		{"other mapper", public, testAdminCode, connect.CodeUnknown},
+		{"other mapper parent", public, "not_found.user", connect.CodeNotFound},
+		{"default mapper", cerr.DefaultMapper(), testAdminCode, connect.CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.mapper.ToConnectCode(tt.code); got != tt.want {
+				t.Errorf("ToConnectCode(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	if got := admin.ToErrxCode(connect.CodeFailedPrecondition); got != testAdminCode {
+		t.Errorf("ToErrxCode(FailedPrecondition) = %q, want %q", got, testAdminCode)
+	}
+	if got := public.ToErrxCode(connect.CodeFailedPrecondition); got != errx.FailedPrecondition {
+		t.Errorf("ToErrxCode(FailedPrecondition) = %q, want %q", got, errx.FailedPrecondition)
+	}
+}
+
+func TestMapper_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	var m cerr.Mapper
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			m.Register(testAdminCode, connect.CodeAborted)
+			_ = m.ToConnectCode(testAdminCode)
+			_ = m.ToConnectError(errx.New("locked").WithCode(testAdminCode))
+		})
+	}
+	wg.Wait()
+
+	if got := m.ToConnectCode(testAdminCode); got != connect.CodeAborted {
+		t.Errorf("ToConnectCode(%q) = %v, want %v", testAdminCode, got, connect.CodeAborted)
+	}
+}
+
+func TestNewInterceptor_WithMapper(t *testing.T) {
+	t.Parallel()
+
+	m := cerr.NewMapper()
+	m.Register(testAdminCode, connect.CodeAborted)
+	i := cerr.NewInterceptor(cerr.WithMapper(m))
+
+	inner := i.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		return nil, errx.New("locked").WithCode(testAdminCode)
+	})
+	_, err := inner(t.Context(), newTestRequest(http.Header{}))
+	var ce *connect.Error
+	if !errors.As(err, &ce) {
+		t.Fatal("error should be a *connect.Error")
+	}
+	if ce.Code() != connect.CodeAborted {
+		t.Errorf("code = %v, want Aborted", ce.Code())
+	}
+}
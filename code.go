@@ -1,9 +1,18 @@
 package errx
 
-import "errors"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 // Code is a string-based error classification.
 // Users can define custom codes with plain const declarations; no registration required.
+//
+// Codes form a hierarchy: a dotted code such as "not_found.user" is a child of
+// "not_found", and [NewCode] declares any other parent (e.g. "billing/card_declined"
+// under [FailedPrecondition]). Transports map a code without its own mapping
+// through its parents, and [IsCode] matches children.
 type Code string
 
 // String returns the string representation of the Code.
@@ -12,6 +21,84 @@ func (c Code) String() string { return string(c) }
 // Code implements the Coder interface.
 func (c Code) Code() Code { return c }
 
+// Parent returns the parent of c: the parent declared with [NewCode] or
+// [RegisterCode], or else c without its last dotted segment ("not_found.user" → "not_found").
+// Returns "" for a code without a parent.
+func (c Code) Parent() Code {
+	if info, ok := LookupCode(c); ok && info.Parent != "" {
+		return info.Parent
+	}
+	if i := strings.LastIndexByte(string(c), '.'); i > 0 {
+		return c[:i]
+	}
+	return ""
+}
+
+// Is reports whether c is target or one of its descendants.
+func (c Code) Is(target Code) bool {
+	if target == "" {
+		return c == ""
+	}
+	for ; c != ""; c = c.Parent() {
+		if c == target {
+			return true
+		}
+	}
+	return false
+}
+
+// root returns the topmost ancestor of c, or c itself if it has no parent.
+func (c Code) root() Code {
+	for p := c.Parent(); p != ""; p = c.Parent() {
+		c = p
+	}
+	return c
+}
+
+// in reports whether c or one of its ancestors is listed in codes.
+func (c Code) in(codes []Code) bool {
+	for ; c != ""; c = c.Parent() {
+		for _, x := range codes {
+			if c == x {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// codePattern matches lowercase snake_case segments joined by "." (hierarchy)
+// or "/" (namespace), e.g. "not_found.user" or "billing/card_declined".
+var codePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*([./][a-z][a-z0-9_]*)*$`)
+
+// ValidateCode reports whether c is well-formed: lowercase snake_case segments
+// joined by "." or "/".
+func ValidateCode(c Code) error {
+	if !codePattern.MatchString(string(c)) {
+		return fmt.Errorf("errx: malformed code %q", string(c))
+	}
+	return nil
+}
+
+// NewCode declares code name as a child of parent and returns it.
+// It is a shorthand for [RegisterCode] with only Code and Parent set.
+// Transports map it like parent unless it is registered with a mapping of its own,
+// and [IsCode] with parent matches it. A dotted name needs no declaration to get
+// the parent its prefix implies, but may be declared to override it.
+// It panics if name or parent is malformed (see [ValidateCode]) or if the
+// declaration would make name its own ancestor.
+// Must be called at program initialization (e.g. in a package-level var), before serving requests.
+//
+//	var CardDeclined = errx.NewCode("billing/card_declined", errx.FailedPrecondition)
+func NewCode(name string, parent Code) Code {
+	if err := ValidateCode(parent); err != nil {
+		panic(err.Error())
+	}
+	c := Code(name)
+	RegisterCode(CodeInfo{Code: c, Parent: parent})
+	return c
+}
+
 // Built-in codes that map naturally to gRPC/HTTP status codes.
 const (
 	Canceled           Code = "canceled"
@@ -38,16 +125,90 @@ type Coder interface {
 }
 
 // CodeOf extracts the first Code found in the error chain.
-// Returns the zero value ("") if no Coder is found.
+// Layers that do not implement Coder are classified by the resolvers registered
+// with [RegisterCodeResolver], [RegisterCodeFor] and [RegisterCodeForType]; by default
+// [context.Canceled], [context.DeadlineExceeded], [fs.ErrNotExist], [fs.ErrExist],
+// [fs.ErrPermission], [http.ErrHandlerTimeout], [*http.MaxBytesError] and [*net.OpError]
+// are recognized.
+// Layers with an As(any) bool method that yields a Coder (see [errors.As]) are
+// classified by it. Returns the zero value ("") if no Coder is found.
+//
+// When the chain reaches an error with multiple causes (e.g. [Join] or
+// [errors.Join]) before any Coder, each branch is resolved on its own and
+// the first server fault (see [IsServerFault]) wins; if no branch is a server
+// fault, the first non-empty code wins.
 func CodeOf(err error) Code {
+	for err != nil {
+		if c, ok := err.(Coder); ok { //nolint:errorlint // CodeOf implements the unwrapping itself
+			return c.Code()
+		}
+		if c := codeOfAs(err); c != "" {
+			return c
+		}
+		if c := resolveCode(err); c != "" {
+			return c
+		}
+		next, branches := unwrap(err)
+		if branches != nil {
+			return codeOfBranches(branches)
+		}
+		err = next
+	}
+	return ""
+}
+
+// layerCode returns the code of err itself, without unwrapping it: the code of
+// its [Coder], of a Coder it yields through an As method, or the one the registered
+// resolvers give it.
+func layerCode(err error) Code {
+	if c, ok := err.(Coder); ok { //nolint:errorlint // inspects a single layer
+		return c.Code()
+	}
+	if c := codeOfAs(err); c != "" {
+		return c
+	}
+	return resolveCode(err)
+}
+
+// codeOfAs returns the code of the Coder err yields through an As method, or "".
+func codeOfAs(err error) Code {
+	x, ok := err.(interface{ As(any) bool }) //nolint:errorlint // CodeOf implements the unwrapping itself
+	if !ok {
+		return ""
+	}
 	var c Coder
-	if errors.As(err, &c) {
+	if x.As(&c) && c != nil {
 		return c.Code()
 	}
 	return ""
 }
 
-// IsCode reports whether any error in the chain carries the given code.
+// IsServerFault reports whether c is, or descends from, a built-in code that
+// indicates a failure on the server side rather than a problem with the request.
+func IsServerFault(c Code) bool {
+	switch c.root() {
+	case Unknown, Internal, DataLoss, Unavailable, Unimplemented, DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func codeOfBranches(errs []error) Code {
+	var first Code
+	for _, err := range errs {
+		c := CodeOf(err)
+		if IsServerFault(c) {
+			return c
+		}
+		if first == "" {
+			first = c
+		}
+	}
+	return first
+}
+
+// IsCode reports whether the code of err (see [CodeOf]) is code or one of its descendants.
 func IsCode(err error, code Code) bool {
-	return CodeOf(err) == code
+	return CodeOf(err).Is(code)
 }
@@ -1,6 +1,7 @@
 package errx_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -49,6 +50,21 @@ type coderError struct {
 func (e *coderError) Error() string   { return e.msg }
 func (e *coderError) Code() errx.Code { return e.code }
 
+// asCoderError exposes a Coder only through its As method.
+type asCoderError struct {
+	code errx.Code
+}
+
+func (e *asCoderError) Error() string { return "as coder" }
+
+func (e *asCoderError) As(target any) bool {
+	if c, ok := target.(*errx.Coder); ok {
+		*c = &coderError{code: e.code, msg: "as coder"}
+		return true
+	}
+	return false
+}
+
 func TestCodeOf(t *testing.T) {
 	t.Parallel()
 
@@ -82,6 +98,63 @@ func TestCodeOf(t *testing.T) {
 			err:  &coderError{code: errx.Code("payment_required"), msg: "pay up"},
 			want: errx.Code("payment_required"),
 		},
+		{
+			name: "joined: first code when no server fault",
+			err: errors.Join(
+				errors.New("plain"),
+				&coderError{code: errx.InvalidArgument, msg: "bad"},
+				&coderError{code: errx.NotFound, msg: "missing"},
+			),
+			want: errx.InvalidArgument,
+		},
+		{
+			name: "joined: server fault wins",
+			err: errors.Join(
+				&coderError{code: errx.InvalidArgument, msg: "bad"},
+				&coderError{code: errx.Internal, msg: "boom"},
+			),
+			want: errx.Internal,
+		},
+		{
+			name: "multiple %w",
+			err: fmt.Errorf("a: %w, b: %w",
+				errors.New("plain"),
+				&coderError{code: errx.Unavailable, msg: "down"},
+			),
+			want: errx.Unavailable,
+		},
+		{
+			name: "outer code overrides branches",
+			err: errx.Join(
+				&coderError{code: errx.Internal, msg: "boom"},
+			).WithCode(errx.Aborted),
+			want: errx.Aborted,
+		},
+		{
+			name: "coder through As",
+			err:  fmt.Errorf("outer: %w", &asCoderError{code: errx.NotFound}),
+			want: errx.NotFound,
+		},
+		{
+			name: "coder through As in a branch",
+			err:  errors.Join(errors.New("plain"), &asCoderError{code: errx.Unavailable}),
+			want: errx.Unavailable,
+		},
+		{
+			name: "context canceled",
+			err:  fmt.Errorf("query: %w", context.Canceled),
+			want: errx.Canceled,
+		},
+		{
+			name: "context deadline exceeded",
+			err:  errx.Wrap(context.DeadlineExceeded, "op", "query"),
+			want: errx.DeadlineExceeded,
+		},
+		{
+			name: "outer code overrides context error",
+			err:  errx.Wrap(context.Canceled).WithCode(errx.Aborted),
+			want: errx.Aborted,
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,7 +178,124 @@ func TestIsCode(t *testing.T) {
 	if errx.IsCode(err, errx.NotFound) {
 		t.Error("IsCode should return false for non-matching code")
 	}
+	if !errx.IsCode(errx.New("no user").WithCode("not_found.user"), errx.NotFound) {
+		t.Error("IsCode should return true for a child code")
+	}
+	if errx.IsCode(errx.New("missing").WithCode(errx.NotFound), "not_found.user") {
+		t.Error("IsCode should return false for a parent code")
+	}
 	if errx.IsCode(nil, errx.NotFound) {
 		t.Error("IsCode should return false for nil error")
 	}
 }
+
+func TestIsServerFault(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []errx.Code{errx.Internal, errx.Unknown, errx.DataLoss, errx.Unavailable} {
+		if !errx.IsServerFault(c) {
+			t.Errorf("IsServerFault(%q) = false, want true", c)
+		}
+	}
+	for _, c := range []errx.Code{errx.NotFound, errx.InvalidArgument, errx.Code("custom"), ""} {
+		if errx.IsServerFault(c) {
+			t.Errorf("IsServerFault(%q) = true, want false", c)
+		}
+	}
+}
+
+var codeCardDeclined = errx.NewCode("billing/card_declined", errx.FailedPrecondition)
+
+func TestCode_Parent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code errx.Code
+		want errx.Code
+	}{
+		{errx.NotFound, ""},
+		{"not_found.user", errx.NotFound},
+		{"not_found.user.email", "not_found.user"},
+		{codeCardDeclined, errx.FailedPrecondition},
+		{codeCardDeclined + ".expired", codeCardDeclined},
+		{"billing/other", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			t.Parallel()
+			if got := tt.code.Parent(); got != tt.want {
+				t.Errorf("Parent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCode_Is(t *testing.T) {
+	t.Parallel()
+
+	if !(codeCardDeclined + ".expired").Is(errx.FailedPrecondition) {
+		t.Error("grandchild should descend from FailedPrecondition")
+	}
+	if errx.FailedPrecondition.Is(codeCardDeclined) {
+		t.Error("parent should not descend from its child")
+	}
+	if !errx.IsServerFault("internal.db") {
+		t.Error("children of Internal should be server faults")
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code    errx.Code
+		wantErr bool
+	}{
+		{"not_found", false},
+		{"not_found.user", false},
+		{"billing/card_declined", false},
+		{"v2/billing/card_declined.expired", false},
+		{"", true},
+		{"NotFound", true},
+		{"not_found.", true},
+		{".user", true},
+		{"billing//card", true},
+		{"not found", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			t.Parallel()
+			if err := errx.ValidateCode(tt.code); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCode(%q) = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewCode_Panics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		code   string
+		parent errx.Code
+	}{
+		{"malformed name", "Card Declined", errx.FailedPrecondition},
+		{"malformed parent", "card_declined", "Failed"},
+		{"cycle", "not_found", "not_found.user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewCode(%q, %q) did not panic", tt.code, tt.parent)
+				}
+			}()
+			errx.NewCode(tt.code, tt.parent)
+		})
+	}
+}
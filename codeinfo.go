@@ -0,0 +1,203 @@
+package errx
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// CodeInfo declares a code once, with its documentation, defaults and
+// transport mappings. Register it with [RegisterCode]; gerr, cerr and herr,
+// [PublicMessage] and [IsRetryable] all consult the registry.
+//
+// Zero fields are not set. Codes inherit unset defaults and mappings from their
+// nearest ancestor that sets them (see [Code.Parent]).
+type CodeInfo struct {
+	// Code is the code being declared.
+	Code Code
+
+	// Parent is the parent of Code. Dotted codes default to their prefix.
+	Parent Code
+
+	// Description documents the code, e.g. for generated error catalogs.
+	Description string
+
+	// PublicMessage is the client-safe message of errors with this code that have
+	// none set with [Error.WithPublicMessage].
+	PublicMessage string
+
+	// Severity is the default severity of errors with this code.
+	Severity Severity
+
+	// Retryable marks errors with this code (and its descendants) as retryable or not.
+	Retryable Retryability
+
+	// HTTPStatus is the HTTP status code herr responds with.
+	HTTPStatus int
+
+	// GRPCCode is the numeric gRPC status code gerr responds with (e.g. 9 for FailedPrecondition).
+	GRPCCode uint32
+
+	// ConnectCode is the numeric Connect code cerr responds with.
+	ConnectCode uint32
+}
+
+var codeRegistry = struct {
+	mu    sync.RWMutex
+	infos map[Code]CodeInfo
+}{infos: map[Code]CodeInfo{}}
+
+func init() {
+	for _, info := range []CodeInfo{
+		{
+			Code: Canceled, Severity: SeverityInfo,
+			Description: "The operation was canceled, typically by the caller.",
+		},
+		{
+			Code: Unknown, Severity: SeverityError,
+			Description: "Unknown error.",
+		},
+		{
+			Code: InvalidArgument, Severity: SeverityInfo,
+			Description: "The client specified an invalid argument.",
+		},
+		{
+			Code: DeadlineExceeded, Severity: SeverityError,
+			Description: "The deadline expired before the operation could complete.",
+		},
+		{
+			Code: NotFound, Severity: SeverityInfo,
+			Description: "A requested entity was not found.",
+		},
+		{
+			Code: AlreadyExists, Severity: SeverityInfo,
+			Description: "The entity that a client attempted to create already exists.",
+		},
+		{
+			Code: PermissionDenied, Severity: SeverityInfo,
+			Description: "The caller does not have permission to execute the operation.",
+		},
+		{
+			Code: ResourceExhausted, Severity: SeverityWarn, Retryable: Retryable,
+			Description: "Some resource has been exhausted, e.g. a quota.",
+		},
+		{
+			Code: FailedPrecondition, Severity: SeverityInfo,
+			Description: "The system is not in a state required for the operation.",
+		},
+		{
+			Code: Aborted, Severity: SeverityWarn, Retryable: Retryable,
+			Description: "The operation was aborted, typically due to a concurrency conflict.",
+		},
+		{
+			Code: OutOfRange, Severity: SeverityInfo,
+			Description: "The operation was attempted past the valid range.",
+		},
+		{
+			Code: Unimplemented, Severity: SeverityError,
+			Description: "The operation is not implemented or not supported.",
+		},
+		{
+			Code: Internal, Severity: SeverityError,
+			Description: "Internal error.",
+		},
+		{
+			Code: Unavailable, Severity: SeverityError, Retryable: Retryable,
+			Description: "The service is currently unavailable.",
+		},
+		{
+			Code: DataLoss, Severity: SeverityCritical,
+			Description: "Unrecoverable data loss or corruption.",
+		},
+		{
+			Code: Unauthenticated, Severity: SeverityInfo,
+			Description: "The request does not have valid authentication credentials.",
+		},
+	} {
+		RegisterCode(info)
+	}
+}
+
+// RegisterCode declares a code in the registry. Re-declaring a code, including a
+// built-in one, merges into the previous declaration: only the fields set in info
+// override it. A code only needs to be declared to set metadata or mappings; plain const codes
+// work without it.
+// It panics if Code or a non-empty Parent is malformed (see [ValidateCode]) or if
+// Parent would make Code its own ancestor.
+// Must be called at program initialization (e.g. in init()), before serving requests.
+//
+//	errx.RegisterCode(errx.CodeInfo{
+//	    Code:          "payment_required",
+//	    Description:   "The account has no active subscription.",
+//	    PublicMessage: "Please upgrade your plan.",
+//	    HTTPStatus:    http.StatusPaymentRequired,
+//	})
+func RegisterCode(info CodeInfo) {
+	if err := ValidateCode(info.Code); err != nil {
+		panic(err.Error())
+	}
+	if info.Parent != "" {
+		if err := ValidateCode(info.Parent); err != nil {
+			panic(err.Error())
+		}
+		if info.Parent.Is(info.Code) {
+			panic(fmt.Sprintf("errx: code %q cannot be a descendant of itself", info.Code))
+		}
+	}
+	codeRegistry.mu.Lock()
+	defer codeRegistry.mu.Unlock()
+	if prev, ok := codeRegistry.infos[info.Code]; ok {
+		info = mergeCodeInfo(prev, info)
+	}
+	codeRegistry.infos[info.Code] = info
+}
+
+// mergeCodeInfo returns prev with the fields set in next overriding it.
+func mergeCodeInfo(prev, next CodeInfo) CodeInfo {
+	return CodeInfo{
+		Code:          prev.Code,
+		Parent:        cmp.Or(next.Parent, prev.Parent),
+		Description:   cmp.Or(next.Description, prev.Description),
+		PublicMessage: cmp.Or(next.PublicMessage, prev.PublicMessage),
+		Severity:      cmp.Or(next.Severity, prev.Severity),
+		Retryable:     cmp.Or(next.Retryable, prev.Retryable),
+		HTTPStatus:    cmp.Or(next.HTTPStatus, prev.HTTPStatus),
+		GRPCCode:      cmp.Or(next.GRPCCode, prev.GRPCCode),
+		ConnectCode:   cmp.Or(next.ConnectCode, prev.ConnectCode),
+	}
+}
+
+// LookupCode returns the CodeInfo registered for c.
+// Inherited values are not filled in; see [CodeInfo].
+func LookupCode(c Code) (CodeInfo, bool) {
+	codeRegistry.mu.RLock()
+	defer codeRegistry.mu.RUnlock()
+	info, ok := codeRegistry.infos[c]
+	return info, ok
+}
+
+// Codes returns every registered code, including the built-in ones, sorted by code.
+func Codes() []CodeInfo {
+	codeRegistry.mu.RLock()
+	infos := make([]CodeInfo, 0, len(codeRegistry.infos))
+	for _, info := range codeRegistry.infos {
+		infos = append(infos, info)
+	}
+	codeRegistry.mu.RUnlock()
+	slices.SortFunc(infos, func(a, b CodeInfo) int { return cmp.Compare(a.Code, b.Code) })
+	return infos
+}
+
+// inheritedInfo returns the first non-zero value pick returns for c and its ancestors.
+func inheritedInfo[T comparable](c Code, pick func(CodeInfo) T) T {
+	var zero T
+	for ; c != ""; c = c.Parent() {
+		if info, ok := LookupCode(c); ok {
+			if v := pick(info); v != zero {
+				return v
+			}
+		}
+	}
+	return zero
+}
@@ -0,0 +1,125 @@
+package errx_test
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+const (
+	codeQuotaExceeded errx.Code = "billing/quota_exceeded"
+	codeMaintenance   errx.Code = "unavailable.maintenance"
+)
+
+func init() {
+	errx.RegisterCode(errx.CodeInfo{
+		Code:          codeQuotaExceeded,
+		Parent:        errx.ResourceExhausted,
+		Description:   "The monthly quota of the plan is used up.",
+		PublicMessage: "Your plan's quota is used up.",
+		Severity:      errx.SeverityWarn,
+		HTTPStatus:    402,
+	})
+	errx.RegisterCode(errx.CodeInfo{
+		Code:      codeMaintenance,
+		Retryable: errx.NotRetryable,
+	})
+}
+
+func TestRegisterCode_Merges(t *testing.T) { //nolint:paralleltest // re-declares a built-in code
+	orig, _ := errx.LookupCode(errx.NotFound)
+	errx.RegisterCode(errx.CodeInfo{Code: errx.NotFound, Description: "No such entity."})
+	t.Cleanup(func() { errx.RegisterCode(orig) })
+
+	info, _ := errx.LookupCode(errx.NotFound)
+	if info.Description != "No such entity." {
+		t.Errorf("Description = %q, want %q", info.Description, "No such entity.")
+	}
+	if info.Severity != orig.Severity {
+		t.Errorf("Severity = %v, want %v", info.Severity, orig.Severity)
+	}
+	if got := errx.SeverityOf(errx.New("no user").WithCode(errx.NotFound)); got != errx.SeverityInfo {
+		t.Errorf("SeverityOf() = %v, want %v", got, errx.SeverityInfo)
+	}
+}
+
+func TestLookupCode(t *testing.T) {
+	t.Parallel()
+
+	info, ok := errx.LookupCode(codeQuotaExceeded)
+	if !ok {
+		t.Fatal("LookupCode() ok = false")
+	}
+	if info.Description != "The monthly quota of the plan is used up." || info.HTTPStatus != 402 {
+		t.Errorf("LookupCode() = %+v", info)
+	}
+	if _, ok := errx.LookupCode("billing/unregistered"); ok {
+		t.Error("LookupCode() ok = true for an unregistered code")
+	}
+	if codeQuotaExceeded.Parent() != errx.ResourceExhausted {
+		t.Errorf("Parent() = %q, want %q", codeQuotaExceeded.Parent(), errx.ResourceExhausted)
+	}
+}
+
+func TestCodes(t *testing.T) {
+	t.Parallel()
+
+	infos := errx.Codes()
+	if !slices.IsSortedFunc(infos, func(a, b errx.CodeInfo) int {
+		return cmp.Compare(a.Code, b.Code)
+	}) {
+		t.Error("Codes() is not sorted")
+	}
+	for _, c := range []errx.Code{errx.NotFound, errx.Internal, codeQuotaExceeded} {
+		if !slices.ContainsFunc(infos, func(info errx.CodeInfo) bool { return info.Code == c }) {
+			t.Errorf("Codes() does not contain %q", c)
+		}
+	}
+}
+
+func TestCodeInfo_Defaults(t *testing.T) {
+	t.Parallel()
+
+	err := errx.New("quota of org 42 exceeded").WithCode(codeQuotaExceeded + ".storage")
+
+	if got := errx.PublicMessage(err); got != "Your plan's quota is used up." {
+		t.Errorf("PublicMessage() = %q", got)
+	}
+	if !errx.IsRetryable(err) {
+		t.Error("IsRetryable() = false, want retryable inherited from ResourceExhausted")
+	}
+	if errx.IsRetryable(errx.New("bad").WithCode(errx.InvalidArgument)) {
+		t.Error("IsRetryable() = true for InvalidArgument")
+	}
+	if errx.IsRetryable(errx.New("down for maintenance").WithCode(codeMaintenance + ".db")) {
+		t.Error("IsRetryable() = true for a code declared NotRetryable under Unavailable")
+	}
+}
+
+func TestRegisterCode_Panics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		info errx.CodeInfo
+	}{
+		{"empty code", errx.CodeInfo{}},
+		{"malformed code", errx.CodeInfo{Code: "Quota Exceeded"}},
+		{"malformed parent", errx.CodeInfo{Code: "quota", Parent: "Resource"}},
+		{"cycle", errx.CodeInfo{Code: errx.ResourceExhausted, Parent: codeQuotaExceeded}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterCode(%+v) did not panic", tt.info)
+				}
+			}()
+			errx.RegisterCode(tt.info)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package errx
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// ContextExtractor returns the fields to attach to an error created with a context,
+// e.g. a request ID or tenant ID stored in it. It may return nil.
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+var contextExtractors atomic.Pointer[[]ContextExtractor]
+
+// RegisterContextExtractor registers an extractor whose fields [NewCtx], [WrapCtx],
+// [Error.WithContext] and [FromContext] attach to errors.
+// Must be called at program initialization (e.g. in init()), before creating errors.
+func RegisterContextExtractor(fn ContextExtractor) {
+	if fn == nil {
+		return
+	}
+	var fns []ContextExtractor
+	if old := contextExtractors.Load(); old != nil {
+		fns = *old
+	}
+	fns = append(fns[:len(fns):len(fns)], fn)
+	contextExtractors.Store(&fns)
+}
+
+// RegisterContextKey registers an extractor that attaches the value stored in the
+// context under key as the field name, if there is one.
+// Must be called at program initialization (e.g. in init()), before creating errors.
+//
+//	errx.RegisterContextKey("request_id", requestIDKey{})
+func RegisterContextKey(name string, key any) {
+	RegisterContextExtractor(func(ctx context.Context) []slog.Attr {
+		if v := ctx.Value(key); v != nil {
+			return []slog.Attr{slog.Any(name, v)}
+		}
+		return nil
+	})
+}
+
+// contextFields returns the fields the registered extractors take from ctx,
+// leaving out keys that are already in fields or fields of err.
+func contextFields(ctx context.Context, err error, fields []slog.Attr) []slog.Attr {
+	fns := contextExtractors.Load()
+	if fns == nil || ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	for _, fn := range *fns {
+		attrs = append(attrs, fn(ctx)...)
+	}
+	if len(attrs) == 0 {
+		return attrs
+	}
+	var existing []slog.Attr
+	if err != nil {
+		existing = Fields(err)
+	}
+	kept := attrs[:0]
+	for _, a := range attrs {
+		if !hasKey(fields, a.Key) && !hasKey(existing, a.Key) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCtx is like [New] but also attaches the fields that the registered
+// [ContextExtractor]s take from ctx, after args. Keys already in args are not attached again.
+func NewCtx(ctx context.Context, msg string, args ...any) *Error {
+	fields := argsToAttrs(args)
+	ctxFields := contextFields(ctx, nil, fields)
+	return &Error{
+		msg:     msg,
+		fields:  append(fields, ctxFields...),
+		stack:   autoStack(nil, ""),
+		ctxKeys: attrKeys(ctxFields),
+	}
+}
+
+// WrapCtx is like [Wrap] but also attaches the fields that the registered
+// [ContextExtractor]s take from ctx, after args. Keys already in args or in the
+// chain of err (e.g. from an inner NewCtx) are not attached again.
+// Returns nil if err is nil.
+func WrapCtx(ctx context.Context, err error, args ...any) *Error {
+	if err == nil {
+		return nil
+	}
+	fields := argsToAttrs(args)
+	ctxFields := contextFields(ctx, err, fields)
+	return &Error{
+		cause:   err,
+		fields:  append(fields, ctxFields...),
+		stack:   autoStack(err, ""),
+		ctxKeys: attrKeys(ctxFields),
+	}
+}
+
+// WithContext returns a copy of the error with the fields that the registered
+// [ContextExtractor]s take from ctx appended, leaving out keys already present in its chain.
+func (e *Error) WithContext(ctx context.Context) *Error {
+	ctxFields := contextFields(ctx, e, nil)
+	cp := e.With(attrsToArgs(ctxFields)...)
+	cp.ctxKeys = append(slices.Clip(e.ctxKeys), attrKeys(ctxFields)...)
+	return cp
+}
+
+// attrKeys returns the keys of attrs, or nil if there are none.
+func attrKeys(attrs []slog.Attr) []string {
+	var keys []string
+	for _, a := range attrs {
+		keys = append(keys, a.Key)
+	}
+	return keys
+}
+
+func attrsToArgs(attrs []slog.Attr) []any {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
+
+// FromContext returns an Error for a context that is done, or nil if it is not.
+// The Error wraps [context.Cause] and has code [Canceled] or [DeadlineExceeded]
+// depending on [context.Context.Err], even if a custom cause was given
+// (e.g. with [context.WithCancelCause]). Like [NewCtx], it attaches the fields
+// of the registered [ContextExtractor]s.
+//
+//	if err := errx.FromContext(ctx); err != nil {
+//	    return err
+//	}
+func FromContext(ctx context.Context) *Error {
+	ctxErr := ctx.Err()
+	if ctxErr == nil {
+		return nil
+	}
+	code := Canceled
+	if ctxErr == context.DeadlineExceeded { //nolint:errorlint // Err returns the sentinel itself
+		code = DeadlineExceeded
+	}
+	cause := context.Cause(ctx)
+	if cause == nil {
+		cause = ctxErr
+	}
+	fields := contextFields(ctx, cause, nil)
+	return &Error{
+		cause:   cause,
+		code:    code,
+		fields:  fields,
+		stack:   autoStack(cause, code),
+		ctxKeys: attrKeys(fields),
+	}
+}
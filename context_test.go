@@ -0,0 +1,215 @@
+package errx_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mickamy/errx"
+)
+
+type (
+	requestIDKey struct{}
+	tenantKey    struct{}
+)
+
+func init() {
+	errx.RegisterContextKey("request_id", requestIDKey{})
+	errx.RegisterContextExtractor(func(ctx context.Context) []slog.Attr {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []slog.Attr{slog.String("tenant", tenant)}
+		}
+		return nil
+	})
+}
+
+func requestContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, "req-1")
+	return context.WithValue(ctx, tenantKey{}, "acme")
+}
+
+func fieldKeys(err error) []string {
+	var keys []string
+	for _, a := range errx.Fields(err) {
+		keys = append(keys, a.Key)
+	}
+	return keys
+}
+
+func TestContextFields(t *testing.T) {
+	t.Parallel()
+
+	ctx := requestContext(t.Context())
+	cause := errors.New("boom")
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "NewCtx",
+			err:  errx.NewCtx(ctx, "failed", "user_id", 42),
+			want: []string{"user_id", "request_id", "tenant"},
+		},
+		{
+			name: "NewCtx with an extracted key in args",
+			err:  errx.NewCtx(ctx, "failed", "request_id", "req-0"),
+			want: []string{"request_id", "tenant"},
+		},
+		{
+			name: "NewCtx without values",
+			err:  errx.NewCtx(t.Context(), "failed"),
+			want: nil,
+		},
+		{
+			name: "WrapCtx",
+			err:  errx.WrapCtx(ctx, cause, "op", "save"),
+			want: []string{"op", "request_id", "tenant"},
+		},
+		{
+			name: "WrapCtx with an extracted key in args",
+			err:  errx.WrapCtx(ctx, cause, "tenant", "globex"),
+			want: []string{"tenant", "request_id"},
+		},
+		{
+			name: "WrapCtx over NewCtx",
+			err:  errx.WrapCtx(ctx, errx.NewCtx(ctx, "failed")),
+			want: []string{"request_id", "tenant"},
+		},
+		{
+			name: "WithContext",
+			err:  errx.New("failed", "user_id", 42).WithContext(ctx),
+			want: []string{"user_id", "request_id", "tenant"},
+		},
+		{
+			name: "WithContext partial",
+			err:  errx.New("failed").WithContext(context.WithValue(t.Context(), tenantKey{}, "acme")),
+			want: []string{"tenant"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := fieldKeys(tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("field keys = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("field keys = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestContextFields_Values(t *testing.T) {
+	t.Parallel()
+
+	err := errx.NewCtx(requestContext(t.Context()), "failed")
+	attr := errx.SlogAttr(err)
+	found := false
+	for _, a := range attr.Value.Group() {
+		if a.Key == "request_id" && a.Value.String() == "req-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("SlogAttr() = %v, want request_id=req-1", attr)
+	}
+}
+
+func TestWrapCtx_Nil(t *testing.T) {
+	t.Parallel()
+
+	if err := errx.WrapCtx(t.Context(), nil); err != nil {
+		t.Errorf("WrapCtx(nil) = %v, want nil", err)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	errShutdown := errors.New("shutting down")
+
+	tests := []struct {
+		name      string
+		ctx       func() context.Context
+		wantCode  errx.Code
+		wantCause error
+	}{
+		{
+			name: "canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(t.Context())
+				cancel()
+				return ctx
+			},
+			wantCode:  errx.Canceled,
+			wantCause: context.Canceled,
+		},
+		{
+			name: "deadline exceeded",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithDeadline(t.Context(), time.Now().Add(-time.Second))
+				t.Cleanup(cancel)
+				return ctx
+			},
+			wantCode:  errx.DeadlineExceeded,
+			wantCause: context.DeadlineExceeded,
+		},
+		{
+			name: "custom cause",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancelCause(t.Context())
+				cancel(errShutdown)
+				return ctx
+			},
+			wantCode:  errx.Canceled,
+			wantCause: errShutdown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := errx.FromContext(tt.ctx())
+			if err == nil {
+				t.Fatal("FromContext() = nil")
+			}
+			if got := err.Code(); got != tt.wantCode {
+				t.Errorf("Code() = %q, want %q", got, tt.wantCode)
+			}
+			if !errors.Is(err, tt.wantCause) {
+				t.Errorf("errors.Is(err, %v) = false", tt.wantCause)
+			}
+			if keys := fieldKeys(err); len(keys) != 0 {
+				t.Errorf("Fields() keys = %v, want none", keys)
+			}
+		})
+	}
+}
+
+func TestFromContext_NotDone(t *testing.T) {
+	t.Parallel()
+
+	if err := errx.FromContext(t.Context()); err != nil {
+		t.Errorf("FromContext() = %v, want nil", err)
+	}
+}
+
+func TestFromContext_Fields(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(requestContext(t.Context()))
+	cancel()
+	got := fieldKeys(errx.FromContext(ctx))
+	if len(got) != 2 || got[0] != "request_id" || got[1] != "tenant" {
+		t.Errorf("Fields() keys = %v, want [request_id tenant]", got)
+	}
+}
@@ -1,14 +1,22 @@
 package errx
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // BadRequestDetail describes violations in a client request.
 type BadRequestDetail struct {
-	Violations []BadRequestFieldViolation
+	Violations []BadRequestFieldViolation `json:"violations"`
 }
 
 // BadRequestFieldViolation describes a single field-level violation.
 type BadRequestFieldViolation struct {
-	Field       string
-	Description string
+	Field       string `json:"field"`
+	Description string `json:"description"`
 }
 
 // FieldViolation creates a BadRequestDetail with a single field violation.
@@ -25,14 +33,14 @@ func BadRequest(violations ...BadRequestFieldViolation) *BadRequestDetail {
 
 // PreconditionFailureDetail describes what preconditions were not met.
 type PreconditionFailureDetail struct {
-	Violations []PreconditionViolation
+	Violations []PreconditionViolation `json:"violations"`
 }
 
 // PreconditionViolation describes a single precondition violation.
 type PreconditionViolation struct {
-	Type        string
-	Subject     string
-	Description string
+	Type        string `json:"type"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
 }
 
 // PreconditionFailure creates a PreconditionFailureDetail with the given violations.
@@ -42,10 +50,10 @@ func PreconditionFailure(violations ...PreconditionViolation) *PreconditionFailu
 
 // ResourceInfoDetail describes the resource that is being accessed.
 type ResourceInfoDetail struct {
-	ResourceType string
-	ResourceName string
-	Owner        string
-	Description  string
+	ResourceType string `json:"resource_type"`
+	ResourceName string `json:"resource_name"`
+	Owner        string `json:"owner"`
+	Description  string `json:"description"`
 }
 
 // ResourceInfo creates a ResourceInfoDetail.
@@ -60,12 +68,14 @@ func ResourceInfo(resourceType, resourceName, owner, description string) *Resour
 
 // ErrorInfoDetail describes the cause of the error with structured details.
 type ErrorInfoDetail struct {
-	Reason   string
-	Domain   string
-	Metadata map[string]string
+	Reason   string            `json:"reason"`
+	Domain   string            `json:"domain"`
+	Metadata map[string]string `json:"metadata"`
 }
 
 // ErrorInfo creates an ErrorInfoDetail.
+// The metadata is kept as given; values of keys registered with [RegisterSensitiveKeys]
+// are redacted when the detail is output (%+v, JSON, gerr, cerr and herr).
 func ErrorInfo(reason, domain string, metadata map[string]string) *ErrorInfoDetail {
 	return &ErrorInfoDetail{
 		Reason:   reason,
@@ -73,3 +83,132 @@ func ErrorInfo(reason, domain string, metadata map[string]string) *ErrorInfoDeta
 		Metadata: metadata,
 	}
 }
+
+// Redacted returns d, or a copy of it with the values of sensitive metadata keys
+// (see [RegisterSensitiveKeys]) redacted if it has any.
+func (d *ErrorInfoDetail) Redacted() *ErrorInfoDetail {
+	for k := range d.Metadata {
+		if IsSensitiveKey(k) {
+			cp := *d
+			cp.Metadata = redactMetadata(d.Metadata)
+			return &cp
+		}
+	}
+	return d
+}
+
+// MarshalJSON implements json.Marshaler. Values of sensitive metadata keys are redacted.
+func (d *ErrorInfoDetail) MarshalJSON() ([]byte, error) {
+	type plain ErrorInfoDetail
+	b, err := json.Marshal((*plain)(d.Redacted()))
+	if err != nil {
+		return nil, fmt.Errorf("errx: marshal ErrorInfo: %w", err)
+	}
+	return b, nil
+}
+
+// QuotaFailureDetail describes how a quota check failed.
+type QuotaFailureDetail struct {
+	Violations []QuotaViolation `json:"violations"`
+}
+
+// QuotaViolation describes a single quota violation.
+type QuotaViolation struct {
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+}
+
+// QuotaFailure creates a QuotaFailureDetail with the given violations.
+func QuotaFailure(violations ...QuotaViolation) *QuotaFailureDetail {
+	return &QuotaFailureDetail{Violations: violations}
+}
+
+// RetryInfoDetail describes when the client may retry a failed request.
+// In JSON the delay is encoded like a google.protobuf.Duration (e.g. "1.5s").
+type RetryInfoDetail struct {
+	RetryDelay time.Duration
+}
+
+// RetryInfo creates a RetryInfoDetail with the given retry delay.
+func RetryInfo(retryDelay time.Duration) *RetryInfoDetail {
+	return &RetryInfoDetail{RetryDelay: retryDelay}
+}
+
+type retryInfoJSON struct {
+	RetryDelay string `json:"retry_delay"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d *RetryInfoDetail) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(retryInfoJSON{
+		RetryDelay: strconv.FormatFloat(d.RetryDelay.Seconds(), 'f', -1, 64) + "s",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("errx: marshal RetryInfo: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *RetryInfoDetail) UnmarshalJSON(data []byte) error {
+	var v retryInfoJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("errx: unmarshal RetryInfo: %w", err)
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSuffix(v.RetryDelay, "s"), 64)
+	if err != nil {
+		return fmt.Errorf("errx: unmarshal RetryInfo: invalid retry_delay %q", v.RetryDelay)
+	}
+	d.RetryDelay = time.Duration(secs * float64(time.Second))
+	return nil
+}
+
+// DebugInfoDetail describes additional debugging information.
+type DebugInfoDetail struct {
+	StackEntries []string `json:"stack_entries"`
+	Detail       string   `json:"detail"`
+}
+
+// DebugInfo creates a DebugInfoDetail.
+func DebugInfo(stackEntries []string, detail string) *DebugInfoDetail {
+	return &DebugInfoDetail{StackEntries: stackEntries, Detail: detail}
+}
+
+// HelpDetail provides links to documentation or for performing an out-of-band action.
+type HelpDetail struct {
+	Links []HelpLink `json:"links"`
+}
+
+// HelpLink describes a single URL link.
+type HelpLink struct {
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// Help creates a HelpDetail with the given links.
+func Help(links ...HelpLink) *HelpDetail {
+	return &HelpDetail{Links: links}
+}
+
+// RequestInfoDetail contains metadata about the request that clients can attach
+// when filing a bug or providing other forms of feedback.
+type RequestInfoDetail struct {
+	RequestID   string `json:"request_id"`
+	ServingData string `json:"serving_data"`
+}
+
+// RequestInfo creates a RequestInfoDetail.
+func RequestInfo(requestID, servingData string) *RequestInfoDetail {
+	return &RequestInfoDetail{RequestID: requestID, ServingData: servingData}
+}
+
+// LocalizedMessageDetail provides a localized error message that is safe to return to the user.
+type LocalizedMessageDetail struct {
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+}
+
+// LocalizedMessage creates a LocalizedMessageDetail.
+func LocalizedMessage(locale, message string) *LocalizedMessageDetail {
+	return &LocalizedMessageDetail{Locale: locale, Message: message}
+}
@@ -1,7 +1,9 @@
 package errx_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/mickamy/errx"
 )
@@ -89,6 +91,71 @@ func TestErrorInfo(t *testing.T) {
 	}
 }
 
+func TestQuotaFailure(t *testing.T) {
+	t.Parallel()
+
+	d := errx.QuotaFailure(errx.QuotaViolation{Subject: "project:abc", Description: "RPM limit exceeded"})
+	if len(d.Violations) != 1 {
+		t.Fatalf("violations length = %d, want 1", len(d.Violations))
+	}
+	if v := d.Violations[0]; v.Subject != "project:abc" || v.Description != "RPM limit exceeded" {
+		t.Errorf("got %+v", v)
+	}
+}
+
+func TestRetryInfo_JSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(errx.RetryInfo(1500 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"retry_delay":"1.5s"}` {
+		t.Errorf("json = %s, want %s", b, `{"retry_delay":"1.5s"}`)
+	}
+
+	var d errx.RetryInfoDetail
+	if err := json.Unmarshal(b, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.RetryDelay != 1500*time.Millisecond {
+		t.Errorf("RetryDelay = %v, want %v", d.RetryDelay, 1500*time.Millisecond)
+	}
+
+	if err := json.Unmarshal([]byte(`{"retry_delay":"soon"}`), &d); err == nil {
+		t.Error("Unmarshal should fail for an invalid duration")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	t.Parallel()
+
+	d := errx.Help(
+		errx.HelpLink{Description: "API docs", URL: "https://example.com/docs"},
+		errx.HelpLink{Description: "Status", URL: "https://status.example.com"},
+	)
+	if len(d.Links) != 2 {
+		t.Fatalf("links length = %d, want 2", len(d.Links))
+	}
+	if d.Links[0].URL != "https://example.com/docs" {
+		t.Errorf("links[0].url = %q, want %q", d.Links[0].URL, "https://example.com/docs")
+	}
+}
+
+func TestDebugRequestAndLocalizedInfo(t *testing.T) {
+	t.Parallel()
+
+	if d := errx.DebugInfo([]string{"main.go:42"}, "nil pointer"); len(d.StackEntries) != 1 || d.Detail != "nil pointer" {
+		t.Errorf("DebugInfo = %+v", d)
+	}
+	if d := errx.RequestInfo("req-1", "shard-7"); d.RequestID != "req-1" || d.ServingData != "shard-7" {
+		t.Errorf("RequestInfo = %+v", d)
+	}
+	if d := errx.LocalizedMessage("en", "Hello"); d.Locale != "en" || d.Message != "Hello" {
+		t.Errorf("LocalizedMessage = %+v", d)
+	}
+}
+
 func TestDetailWithError(t *testing.T) {
 	t.Parallel()
 
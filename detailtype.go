@@ -0,0 +1,135 @@
+package errx
+
+import (
+	"reflect"
+	"sync"
+)
+
+// DetailType declares how a detail type is represented on the wire.
+// Register it once with [RegisterDetail]; gerr, cerr, herr and the JSON encoding
+// of [*Error] all consult the registry.
+//
+// Proto conversions are typed as any so that errx does not depend on protobuf;
+// gerr and cerr require ToProto to return a proto.Message.
+type DetailType struct {
+	// Name identifies the type in problem+json ("type" member) and in serialized errors.
+	// The "type" member is reserved; the detail's own JSON encoding must not use it.
+	Name string
+
+	// New returns a pointer to a new zero value of the detail type.
+	// The detail's JSON form (encoding/json) is used for problem+json and serialized errors.
+	New func() any
+
+	// ToProto converts a detail to a proto message. Optional.
+	ToProto func(d any) any
+
+	// FromProto converts a proto message back to the detail type,
+	// reporting false if the message is not one it handles. Optional.
+	FromProto func(m any) (any, bool)
+}
+
+var detailRegistry = struct {
+	mu     sync.RWMutex
+	byType map[reflect.Type]DetailType
+	byName map[string]DetailType
+	order  []DetailType
+	// unknown is called when a transport drops a detail no converter recognizes.
+	unknown func(transport string, detail any)
+}{
+	byType: map[reflect.Type]DetailType{},
+	byName: map[string]DetailType{},
+}
+
+// RegisterDetail registers a detail type. Registering a type or name again replaces
+// the previous registration. It panics if Name is empty or New is nil.
+// Must be called at program initialization (e.g. in init()), before serving requests.
+func RegisterDetail(dt DetailType) {
+	if dt.Name == "" || dt.New == nil {
+		panic("errx: RegisterDetail requires Name and New")
+	}
+	typ := reflect.TypeOf(dt.New())
+
+	detailRegistry.mu.Lock()
+	defer detailRegistry.mu.Unlock()
+	if old, ok := detailRegistry.byType[typ]; ok {
+		delete(detailRegistry.byName, old.Name)
+	}
+	if old, ok := detailRegistry.byName[dt.Name]; ok {
+		delete(detailRegistry.byType, reflect.TypeOf(old.New()))
+	}
+	detailRegistry.byType[typ] = dt
+	detailRegistry.byName[dt.Name] = dt
+	order := make([]DetailType, 0, len(detailRegistry.order)+1)
+	for _, o := range detailRegistry.order {
+		if o.Name != dt.Name && reflect.TypeOf(o.New()) != typ {
+			order = append(order, o)
+		}
+	}
+	detailRegistry.order = append(order, dt)
+}
+
+// LookupDetail returns the registered DetailType of d.
+func LookupDetail(d any) (DetailType, bool) {
+	detailRegistry.mu.RLock()
+	defer detailRegistry.mu.RUnlock()
+	dt, ok := detailRegistry.byType[reflect.TypeOf(d)]
+	return dt, ok
+}
+
+// LookupDetailName returns the DetailType registered under name.
+func LookupDetailName(name string) (DetailType, bool) {
+	detailRegistry.mu.RLock()
+	defer detailRegistry.mu.RUnlock()
+	dt, ok := detailRegistry.byName[name]
+	return dt, ok
+}
+
+// DetailFromProto converts a proto message to a registered detail type using
+// the first matching FromProto converter. It reports false if none matches.
+func DetailFromProto(m any) (any, bool) {
+	detailRegistry.mu.RLock()
+	order := detailRegistry.order
+	detailRegistry.mu.RUnlock()
+	for _, dt := range order {
+		if dt.FromProto == nil {
+			continue
+		}
+		if d, ok := dt.FromProto(m); ok {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// SetUnknownDetailHandler installs a function that is called whenever a transport
+// drops a detail because no converter recognizes it (e.g. to log or count such details).
+// The transport argument is "gerr", "cerr" or "herr". Pass nil to remove the handler.
+func SetUnknownDetailHandler(f func(transport string, detail any)) {
+	detailRegistry.mu.Lock()
+	defer detailRegistry.mu.Unlock()
+	detailRegistry.unknown = f
+}
+
+// ReportUnknownDetail notifies the handler installed by [SetUnknownDetailHandler].
+// Transports call it for every detail they drop.
+func ReportUnknownDetail(transport string, detail any) {
+	detailRegistry.mu.RLock()
+	f := detailRegistry.unknown
+	detailRegistry.mu.RUnlock()
+	if f != nil {
+		f(transport, detail)
+	}
+}
+
+func init() {
+	RegisterDetail(DetailType{Name: "BadRequest", New: func() any { return &BadRequestDetail{} }})
+	RegisterDetail(DetailType{Name: "PreconditionFailure", New: func() any { return &PreconditionFailureDetail{} }})
+	RegisterDetail(DetailType{Name: "ResourceInfo", New: func() any { return &ResourceInfoDetail{} }})
+	RegisterDetail(DetailType{Name: "ErrorInfo", New: func() any { return &ErrorInfoDetail{} }})
+	RegisterDetail(DetailType{Name: "QuotaFailure", New: func() any { return &QuotaFailureDetail{} }})
+	RegisterDetail(DetailType{Name: "RetryInfo", New: func() any { return &RetryInfoDetail{} }})
+	RegisterDetail(DetailType{Name: "DebugInfo", New: func() any { return &DebugInfoDetail{} }})
+	RegisterDetail(DetailType{Name: "Help", New: func() any { return &HelpDetail{} }})
+	RegisterDetail(DetailType{Name: "RequestInfo", New: func() any { return &RequestInfoDetail{} }})
+	RegisterDetail(DetailType{Name: "LocalizedMessage", New: func() any { return &LocalizedMessageDetail{} }})
+}
@@ -0,0 +1,136 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+// planDetail is a custom detail type registered for tests.
+type planDetail struct {
+	Plan string `json:"plan"`
+}
+
+// planProto stands in for a proto message in registry tests.
+type planProto struct {
+	name string
+}
+
+func init() {
+	errx.RegisterDetail(errx.DetailType{
+		Name: "Plan",
+		New:  func() any { return &planDetail{} },
+		ToProto: func(d any) any {
+			return &planProto{name: d.(*planDetail).Plan} //nolint:forcetypeassert // registered type
+		},
+		FromProto: func(m any) (any, bool) {
+			p, ok := m.(*planProto)
+			if !ok {
+				return nil, false
+			}
+			return &planDetail{Plan: p.name}, true
+		},
+	})
+}
+
+func TestLookupDetail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("built-in", func(t *testing.T) {
+		t.Parallel()
+		dt, ok := errx.LookupDetail(errx.FieldViolation("a", "b"))
+		if !ok || dt.Name != "BadRequest" {
+			t.Errorf("LookupDetail = %q, %v; want BadRequest, true", dt.Name, ok)
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+		dt, ok := errx.LookupDetail(&planDetail{})
+		if !ok || dt.Name != "Plan" {
+			t.Errorf("LookupDetail = %q, %v; want Plan, true", dt.Name, ok)
+		}
+		if _, ok := dt.ToProto(&planDetail{Plan: "pro"}).(*planProto); !ok {
+			t.Error("ToProto should return the registered proto type")
+		}
+	})
+
+	t.Run("by name", func(t *testing.T) {
+		t.Parallel()
+		dt, ok := errx.LookupDetailName("ResourceInfo")
+		if !ok {
+			t.Fatal("LookupDetailName should find ResourceInfo")
+		}
+		if _, ok := dt.New().(*errx.ResourceInfoDetail); !ok {
+			t.Errorf("New() type = %T, want *errx.ResourceInfoDetail", dt.New())
+		}
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		t.Parallel()
+		if _, ok := errx.LookupDetail("opaque"); ok {
+			t.Error("LookupDetail should not find an unregistered type")
+		}
+	})
+}
+
+func TestDetailFromProto(t *testing.T) {
+	t.Parallel()
+
+	d, ok := errx.DetailFromProto(&planProto{name: "pro"})
+	if !ok {
+		t.Fatal("DetailFromProto should convert a registered proto")
+	}
+	if pd, ok := d.(*planDetail); !ok || pd.Plan != "pro" {
+		t.Errorf("DetailFromProto = %#v, want &planDetail{Plan: pro}", d)
+	}
+	if _, ok := errx.DetailFromProto("not a proto"); ok {
+		t.Error("DetailFromProto should not convert an unknown message")
+	}
+}
+
+func TestRegisterDetail_Invalid(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterDetail should panic without a Name")
+		}
+	}()
+	errx.RegisterDetail(errx.DetailType{New: func() any { return &planDetail{} }})
+}
+
+func TestReportUnknownDetail(t *testing.T) { //nolint:paralleltest // installs a global handler
+	var gotTransport string
+	var gotDetail any
+	errx.SetUnknownDetailHandler(func(transport string, detail any) {
+		gotTransport, gotDetail = transport, detail
+	})
+	defer errx.SetUnknownDetailHandler(nil)
+
+	errx.ReportUnknownDetail("herr", "opaque")
+	if gotTransport != "herr" || gotDetail != "opaque" {
+		t.Errorf("handler got (%q, %v), want (herr, opaque)", gotTransport, gotDetail)
+	}
+}
+
+func TestError_JSONRoundTrip_CustomDetail(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(errx.New("upgrade needed").WithDetails(&planDetail{Plan: "pro"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errx.Error
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	details := errx.DetailsOf(&decoded)
+	if len(details) != 1 {
+		t.Fatalf("DetailsOf length = %d, want 1", len(details))
+	}
+	if pd, ok := details[0].(*planDetail); !ok || pd.Plan != "pro" {
+		t.Errorf("detail = %#v, want &planDetail{Plan: pro}", details[0])
+	}
+}
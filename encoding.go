@@ -0,0 +1,396 @@
+package errx
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// compile-time checks
+var (
+	_ json.Marshaler             = (*Error)(nil)
+	_ json.Unmarshaler           = (*Error)(nil)
+	_ encoding.BinaryMarshaler   = (*Error)(nil)
+	_ encoding.BinaryUnmarshaler = (*Error)(nil)
+)
+
+// Layer kinds used in the serialized chain.
+const (
+	layerErrx     = "errx"
+	layerSentinel = "sentinel"
+	layerForeign  = "error"
+)
+
+// jsonError is the serialized form of an error chain.
+type jsonError struct {
+	Message string      `json:"message"`
+	Chain   []jsonLayer `json:"chain"`
+}
+
+// jsonLayer is a single layer of the cause chain.
+// errx layers carry their own message, code, fields, details and stack;
+// foreign layers carry the full text of their Error() method and their code (see [layerCode]).
+// Layers with multiple causes carry one chain per branch.
+type jsonLayer struct {
+	Kind       string        `json:"kind"`
+	Msg        string        `json:"msg,omitempty"`
+	Op         Op            `json:"op,omitempty"`
+	Public     string        `json:"public,omitempty"`
+	Code       Code          `json:"code,omitempty"`
+	Retryable  *bool         `json:"retryable,omitempty"`
+	RetryAfter int64         `json:"retry_after,omitempty"` // nanoseconds
+	Severity   Severity      `json:"severity,omitempty"`
+	Fields     []jsonField   `json:"fields,omitempty"`
+	Details    []jsonDetail  `json:"details,omitempty"`
+	Stack      []Frame       `json:"stack,omitempty"`
+	Branches   [][]jsonLayer `json:"branches,omitempty"`
+}
+
+// jsonField is a slog.Attr with its kind preserved.
+type jsonField struct {
+	Key   string          `json:"key"`
+	Kind  string          `json:"kind"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonDetail is a detail object tagged with its type name.
+type jsonDetail struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The encoding contains the full message and the flattened cause chain:
+// every errx layer with its message, code, fields (with their slog kinds),
+// details and stack frames, and every foreign layer with its message and the code
+// it implements or that the registered resolvers give it.
+// Details whose type is not registered with [RegisterDetail] are omitted.
+// [Secret] values and fields with sensitive keys are redacted.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	chain, err := encodeChain(e)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(jsonError{Message: e.Error(), Chain: chain})
+	if err != nil {
+		return nil, fmt.Errorf("errx: marshal error: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The decoded error reproduces the original message, and [CodeOf], [Fields],
+// [DetailsOf] and [StackOf] return the same data as for the encoded error.
+// Foreign layers are restored as opaque errors with the original message;
+// sentinel layers are restored as *Error values with the sentinel's message and code,
+// so errors.Is no longer matches the original sentinel. Like [Error.MarshalJSON],
+// it skips details whose type is not registered with [RegisterDetail], e.g. those of
+// a service that registers more types than this one.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var je jsonError
+	if err := json.Unmarshal(data, &je); err != nil {
+		return fmt.Errorf("errx: unmarshal error: %w", err)
+	}
+	decoded, err := decodeChain(je.Chain)
+	if err != nil {
+		return err
+	}
+	if ex, ok := decoded.(*Error); ok { //nolint:errorlint // decodeChain returns the outermost layer
+		*e = *ex
+		return nil
+	}
+	*e = Error{cause: decoded}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the JSON encoding.
+func (e *Error) MarshalBinary() ([]byte, error) {
+	return e.MarshalJSON()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler using the JSON encoding.
+func (e *Error) UnmarshalBinary(data []byte) error {
+	return e.UnmarshalJSON(data)
+}
+
+func encodeChain(err error) ([]jsonLayer, error) {
+	var chain []jsonLayer
+	for err != nil {
+		l, encErr := encodeLayer(err)
+		if encErr != nil {
+			return nil, encErr
+		}
+		next, branches := unwrap(err)
+		for _, b := range branches {
+			bc, encErr := encodeChain(b)
+			if encErr != nil {
+				return nil, encErr
+			}
+			l.Branches = append(l.Branches, bc)
+		}
+		chain = append(chain, l)
+		err = next
+	}
+	return chain, nil
+}
+
+func encodeLayer(err error) (jsonLayer, error) {
+	switch v := err.(type) { //nolint:errorlint // encoding a single layer, not the chain
+	case *Error:
+		l := jsonLayer{
+			Kind:       layerErrx,
+			Msg:        v.msg,
+			Op:         v.op,
+			Public:     v.publicMsg,
+			Code:       v.code,
+			RetryAfter: int64(v.retryAfter),
+			Severity:   v.severity,
+			Stack:      v.stack.symbolized(),
+		}
+		if v.retry != 0 {
+			retryable := v.retry == Retryable
+			l.Retryable = &retryable
+		}
+		for _, a := range v.fields {
+			f, encErr := encodeField(redactAttr(a))
+			if encErr != nil {
+				return jsonLayer{}, encErr
+			}
+			l.Fields = append(l.Fields, f)
+		}
+		for _, d := range v.details {
+			dt, ok := LookupDetail(d)
+			if !ok {
+				continue
+			}
+			b, encErr := json.Marshal(d)
+			if encErr != nil {
+				return jsonLayer{}, fmt.Errorf("errx: marshal detail %s: %w", dt.Name, encErr)
+			}
+			l.Details = append(l.Details, jsonDetail{Type: dt.Name, Value: b})
+		}
+		return l, nil
+	case *SentinelError:
+		return jsonLayer{Kind: layerSentinel, Msg: v.msg, Code: v.code}, nil
+	default:
+		return jsonLayer{Kind: layerForeign, Msg: err.Error(), Code: layerCode(err)}, nil
+	}
+}
+
+func encodeField(a slog.Attr) (jsonField, error) {
+	v := a.Value.Resolve()
+	var raw any
+	switch v.Kind() {
+	case slog.KindString:
+		raw = v.String()
+	case slog.KindInt64:
+		raw = v.Int64()
+	case slog.KindUint64:
+		raw = v.Uint64()
+	case slog.KindFloat64:
+		raw = v.Float64()
+	case slog.KindBool:
+		raw = v.Bool()
+	case slog.KindDuration:
+		raw = int64(v.Duration())
+	case slog.KindTime:
+		raw = v.Time().Format(time.RFC3339Nano)
+	case slog.KindGroup:
+		group := make([]jsonField, 0, len(v.Group()))
+		for _, ga := range v.Group() {
+			f, err := encodeField(ga)
+			if err != nil {
+				return jsonField{}, err
+			}
+			group = append(group, f)
+		}
+		raw = group
+	case slog.KindAny, slog.KindLogValuer:
+		raw = v.Any()
+		if e, ok := raw.(error); ok {
+			raw = e.Error()
+		}
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		// Values that cannot be represented in JSON fall back to their text form.
+		b, err = json.Marshal(fmt.Sprint(raw))
+		if err != nil {
+			return jsonField{}, fmt.Errorf("errx: marshal field %q: %w", a.Key, err)
+		}
+	}
+	return jsonField{Key: a.Key, Kind: v.Kind().String(), Value: b}, nil
+}
+
+// decodeChain rebuilds a chain from its serialized layers and returns the outermost error.
+func decodeChain(chain []jsonLayer) (decoded error, err error) {
+	var cause error
+	for i := len(chain) - 1; i >= 0; i-- {
+		l := chain[i]
+		switch l.Kind {
+		case layerErrx, layerSentinel:
+			e := &Error{
+				msg:        l.Msg,
+				op:         l.Op,
+				publicMsg:  l.Public,
+				code:       l.Code,
+				retryAfter: time.Duration(l.RetryAfter),
+				severity:   l.Severity,
+				cause:      cause,
+			}
+			if l.Retryable != nil {
+				e.retry = NotRetryable
+				if *l.Retryable {
+					e.retry = Retryable
+				}
+			}
+			for _, jf := range l.Fields {
+				a, fErr := decodeField(jf)
+				if fErr != nil {
+					return nil, fErr
+				}
+				e.fields = append(e.fields, a)
+			}
+			for _, jd := range l.Details {
+				d, dErr := decodeDetail(jd)
+				if dErr != nil {
+					return nil, dErr
+				}
+				if d != nil {
+					e.details = append(e.details, d)
+				}
+			}
+			if len(l.Stack) > 0 {
+				e.stack = &Stack{frames: l.Stack}
+			}
+			cause = e
+		default:
+			if len(l.Branches) == 0 {
+				cause = &remoteError{msg: l.Msg, code: l.Code, cause: cause}
+				continue
+			}
+			je := &remoteJoinError{msg: l.Msg, code: l.Code}
+			for _, bc := range l.Branches {
+				b, bErr := decodeChain(bc)
+				if bErr != nil {
+					return nil, bErr
+				}
+				je.errs = append(je.errs, b)
+			}
+			cause = je
+		}
+	}
+	return cause, nil
+}
+
+func decodeField(jf jsonField) (slog.Attr, error) {
+	var err error
+	var v slog.Value
+	switch jf.Kind {
+	case slog.KindString.String():
+		var s string
+		err = json.Unmarshal(jf.Value, &s)
+		v = slog.StringValue(s)
+	case slog.KindInt64.String():
+		var n int64
+		err = json.Unmarshal(jf.Value, &n)
+		v = slog.Int64Value(n)
+	case slog.KindUint64.String():
+		var n uint64
+		err = json.Unmarshal(jf.Value, &n)
+		v = slog.Uint64Value(n)
+	case slog.KindFloat64.String():
+		var f float64
+		err = json.Unmarshal(jf.Value, &f)
+		v = slog.Float64Value(f)
+	case slog.KindBool.String():
+		var b bool
+		err = json.Unmarshal(jf.Value, &b)
+		v = slog.BoolValue(b)
+	case slog.KindDuration.String():
+		var n int64
+		err = json.Unmarshal(jf.Value, &n)
+		v = slog.DurationValue(time.Duration(n))
+	case slog.KindTime.String():
+		var s string
+		if err = json.Unmarshal(jf.Value, &s); err == nil {
+			var t time.Time
+			t, err = time.Parse(time.RFC3339Nano, s)
+			v = slog.TimeValue(t)
+		}
+	case slog.KindGroup.String():
+		var group []jsonField
+		err = json.Unmarshal(jf.Value, &group)
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, g := range group {
+			a, gErr := decodeField(g)
+			if gErr != nil {
+				return slog.Attr{}, gErr
+			}
+			attrs = append(attrs, a)
+		}
+		v = slog.GroupValue(attrs...)
+	default:
+		var a any
+		err = json.Unmarshal(jf.Value, &a)
+		v = slog.AnyValue(a)
+	}
+	if err != nil {
+		return slog.Attr{}, fmt.Errorf("errx: unmarshal field %q: %w", jf.Key, err)
+	}
+	return slog.Attr{Key: jf.Key, Value: v}, nil
+}
+
+// decodeDetail returns the detail jd encodes, or nil if its type is not registered.
+func decodeDetail(jd jsonDetail) (any, error) {
+	dt, ok := LookupDetailName(jd.Type)
+	if !ok {
+		return nil, nil //nolint:nilnil // unknown detail types are skipped
+	}
+	d := dt.New()
+	if err := json.Unmarshal(jd.Value, d); err != nil {
+		return nil, fmt.Errorf("errx: unmarshal detail %s: %w", jd.Type, err)
+	}
+	return d, nil
+}
+
+// remoteError stands in for a foreign error restored by [Error.UnmarshalJSON].
+// It reproduces the original message and code and keeps the decoded cause reachable.
+type remoteError struct {
+	msg   string
+	code  Code
+	cause error
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.cause }
+
+// Code returns the code of the original error, or that of its cause if it had none.
+func (e *remoteError) Code() Code {
+	if e.code != "" {
+		return e.code
+	}
+	return CodeOf(e.cause)
+}
+
+// remoteJoinError is a [remoteError] for a foreign error with multiple causes.
+type remoteJoinError struct {
+	msg  string
+	code Code
+	errs []error
+}
+
+func (e *remoteJoinError) Error() string   { return e.msg }
+func (e *remoteJoinError) Unwrap() []error { return e.errs }
+
+// Code returns the code of the original error, or that of its causes if it had none.
+func (e *remoteJoinError) Code() Code {
+	if e.code != "" {
+		return e.code
+	}
+	return codeOfBranches(e.errs)
+}
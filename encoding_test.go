@@ -0,0 +1,246 @@
+package errx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mickamy/errx"
+)
+
+func TestError_JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sentinel := errx.NewSentinel("not found", errx.NotFound)
+	inner := errx.Wrap(sentinel,
+		"user_id", 42,
+		"ratio", 0.5,
+		"admin", false,
+		"timeout", 3*time.Second,
+		"at", time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+		slog.Uint64("attempts", 7),
+		slog.Group("req", slog.String("method", "GET"), slog.Int("size", 12)),
+	).WithDetails(
+		errx.ResourceInfo("User", "42", "", "not found"),
+		errx.ErrorInfo("USER_MISSING", "example.com", map[string]string{"id": "42"}),
+	).WithOp("UserRepo.Find").WithStack()
+	outer := errx.Wrapf(fmt.Errorf("repository: %w", inner), "get user").
+		WithOp("UserService.Get").
+		WithCode(errx.NotFound).
+		WithPublicMessage("user not found").
+		WithRetryable(false).
+		WithSeverity(errx.SeverityWarn).
+		WithFieldViolation("id", "unknown")
+
+	b, err := json.Marshal(outer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errx.Error
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, b)
+	}
+
+	assertSameError(t, outer, &decoded)
+}
+
+func TestError_JSONRoundTrip_Join(t *testing.T) {
+	t.Parallel()
+
+	original := errx.Wrap(errx.Join(
+		errx.New("name is required", "field", "name").WithCode(errx.InvalidArgument),
+		errors.New("plain"),
+		errx.New("db down").WithCode(errx.Unavailable).WithStack(),
+	), "request_id", "abc")
+
+	b, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errx.Error
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, b)
+	}
+
+	assertSameError(t, original, &decoded)
+}
+
+func TestError_JSONRoundTrip_ForeignCodes(t *testing.T) {
+	t.Parallel()
+
+	_, openErr := os.Open(filepath.Join(t.TempDir(), "missing"))
+
+	tests := []struct {
+		name string
+		err  *errx.Error
+		want errx.Code
+	}{
+		{"context error", errx.Wrap(context.Canceled), errx.Canceled},
+		{"fs error", errx.Wrap(openErr), errx.NotFound},
+		{
+			"foreign coder",
+			errx.Wrap(fmt.Errorf("repo: %w", &coderError{code: errx.Aborted, msg: "conflict"})),
+			errx.Aborted,
+		},
+		{"joined foreign errors", errx.Wrap(errors.Join(errors.New("plain"), context.Canceled)), errx.Canceled},
+		{
+			"foreign wrapper over errx",
+			errx.Wrap(fmt.Errorf("repo: %w", errx.New("x").WithCode(errx.NotFound))),
+			errx.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := json.Marshal(tt.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded errx.Error
+			if err := json.Unmarshal(b, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v\n%s", err, b)
+			}
+			if got := errx.CodeOf(&decoded); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+			assertSameError(t, tt.err, &decoded)
+		})
+	}
+}
+
+func TestError_JSON_ForeignRoot(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(errx.Wrap(errors.New("connection refused")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errx.Error
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Error() != "connection refused" {
+		t.Errorf("Error() = %q, want %q", decoded.Error(), "connection refused")
+	}
+	if decoded.Unwrap() == nil {
+		t.Error("foreign cause should be restored")
+	}
+}
+
+func TestError_JSON_UnknownDetailsOmitted(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(errx.New("fail").WithDetails("opaque", errx.FieldViolation("a", "b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errx.Error
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	details := errx.DetailsOf(&decoded)
+	if len(details) != 1 {
+		t.Fatalf("DetailsOf length = %d, want 1", len(details))
+	}
+	if _, ok := details[0].(*errx.BadRequestDetail); !ok {
+		t.Errorf("detail type = %T, want *errx.BadRequestDetail", details[0])
+	}
+}
+
+func TestError_JSON_UnknownDetailTypesSkipped(t *testing.T) {
+	t.Parallel()
+
+	var decoded errx.Error
+	data := []byte(`{"chain":[{"kind":"errx","msg":"fail","details":[` +
+		`{"type":"Nope","value":{}},{"type":"BadRequest","value":{"violations":[{"field":"a"}]}}]}]}`)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	details := errx.DetailsOf(&decoded)
+	if len(details) != 1 {
+		t.Fatalf("DetailsOf length = %d, want 1", len(details))
+	}
+	if _, ok := details[0].(*errx.BadRequestDetail); !ok {
+		t.Errorf("detail type = %T, want *errx.BadRequestDetail", details[0])
+	}
+}
+
+func TestError_JSON_Invalid(t *testing.T) {
+	t.Parallel()
+
+	var decoded errx.Error
+	data := []byte(`{"chain":[{"kind":"errx","details":[{"type":"BadRequest","value":[]}]}]}`)
+	if err := json.Unmarshal(data, &decoded); err == nil {
+		t.Error("Unmarshal should fail for a malformed detail")
+	}
+}
+
+func TestError_BinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("boom", "k", "v").WithCode(errx.Internal).WithRetryAfter(time.Second).WithStack()
+	b, err := original.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded errx.Error
+	if err := decoded.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	assertSameError(t, original, &decoded)
+}
+
+func assertSameError(t *testing.T, want, got error) {
+	t.Helper()
+
+	if got.Error() != want.Error() {
+		t.Errorf("Error() = %q, want %q", got.Error(), want.Error())
+	}
+	if errx.CodeOf(got) != errx.CodeOf(want) {
+		t.Errorf("CodeOf() = %q, want %q", errx.CodeOf(got), errx.CodeOf(want))
+	}
+
+	wantFields, gotFields := errx.Fields(want), errx.Fields(got)
+	if len(gotFields) != len(wantFields) {
+		t.Fatalf("Fields length = %d, want %d", len(gotFields), len(wantFields))
+	}
+	for i := range wantFields {
+		if !gotFields[i].Equal(wantFields[i]) {
+			t.Errorf("fields[%d] = %v (%s), want %v (%s)",
+				i, gotFields[i], gotFields[i].Value.Kind(), wantFields[i], wantFields[i].Value.Kind())
+		}
+	}
+
+	if errx.IsRetryable(got) != errx.IsRetryable(want) {
+		t.Errorf("IsRetryable() = %v, want %v", errx.IsRetryable(got), errx.IsRetryable(want))
+	}
+	gotAfter, _ := errx.RetryAfterOf(got)
+	wantAfter, _ := errx.RetryAfterOf(want)
+	if gotAfter != wantAfter {
+		t.Errorf("RetryAfterOf() = %v, want %v", gotAfter, wantAfter)
+	}
+	if gotOps, wantOps := errx.OpsOf(got), errx.OpsOf(want); !slices.Equal(gotOps, wantOps) {
+		t.Errorf("OpsOf() = %v, want %v", gotOps, wantOps)
+	}
+	if errx.SeverityOf(got) != errx.SeverityOf(want) {
+		t.Errorf("SeverityOf() = %v, want %v", errx.SeverityOf(got), errx.SeverityOf(want))
+	}
+	if errx.PublicMessage(got) != errx.PublicMessage(want) {
+		t.Errorf("PublicMessage() = %q, want %q", errx.PublicMessage(got), errx.PublicMessage(want))
+	}
+	if !reflect.DeepEqual(errx.DetailsOf(got), errx.DetailsOf(want)) {
+		t.Errorf("DetailsOf() = %#v, want %#v", errx.DetailsOf(got), errx.DetailsOf(want))
+	}
+	if !reflect.DeepEqual(errx.StackOf(got).Frames(), errx.StackOf(want).Frames()) {
+		t.Errorf("StackOf().Frames() = %v, want %v", errx.StackOf(got).Frames(), errx.StackOf(want).Frames())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 // Err is the common interface implemented by both [*Error] and [*SentinelError].
@@ -31,9 +32,28 @@ type Error struct {
 	msg     string
 	cause   error
 	code    Code
+	op      Op
 	fields  []slog.Attr
 	stack   *Stack
 	details []any
+	tmpl    *Template
+
+	// publicMsg is the client-safe message set by [Error.WithPublicMessage].
+	publicMsg string
+
+	retry      Retryability
+	retryAfter time.Duration
+	severity   Severity
+
+	// secretMsg is msg with the [Secret] values it was built from revealed.
+	// It is empty if msg contains no secrets.
+	secretMsg string
+
+	// format is the format string msg was built from by [Wrapf].
+	format string
+
+	// ctxKeys are the keys of the fields taken from a context (see [NewCtx]).
+	ctxKeys []string
 }
 
 // New creates a new Error with the given message and optional structured fields.
@@ -42,6 +62,7 @@ func New(msg string, args ...any) *Error {
 	return &Error{
 		msg:    msg,
 		fields: argsToAttrs(args),
+		stack:  autoStack(nil, ""),
 	}
 }
 
@@ -54,10 +75,24 @@ func Wrap(err error, args ...any) *Error {
 	return &Error{
 		cause:  err,
 		fields: argsToAttrs(args),
+		stack:  autoStack(err, ""),
+	}
+}
+
+// Join returns an Error that wraps the given errors, like [errors.Join].
+// Nil errors are discarded; Join returns nil if every error is nil.
+// Fields, details and stacks are collected from every branch,
+// and the code is resolved as described in [CodeOf].
+func Join(errs ...error) *Error {
+	joined := errors.Join(errs...)
+	if joined == nil {
+		return nil
 	}
+	return &Error{cause: joined}
 }
 
 // Wrapf wraps an existing error with a formatted message.
+// Arguments wrapped in [Secret] are formatted as "[REDACTED]".
 // Additional args beyond the format arguments are not supported;
 // use [Wrap] followed by [Error.With] for structured fields.
 // Returns nil if err is nil.
@@ -65,10 +100,16 @@ func Wrapf(err error, format string, fmtArgs ...any) *Error {
 	if err == nil {
 		return nil
 	}
-	return &Error{
-		msg:   fmt.Sprintf(format, fmtArgs...),
-		cause: err,
+	e := &Error{
+		msg:    fmt.Sprintf(format, fmtArgs...),
+		cause:  err,
+		stack:  autoStack(err, ""),
+		format: format,
 	}
+	if revealed, ok := revealArgs(fmtArgs); ok {
+		e.secretMsg = fmt.Sprintf(format, revealed...)
+	}
+	return e
 }
 
 // With returns a copy of the error with additional structured fields appended.
@@ -79,9 +120,11 @@ func (e *Error) With(args ...any) *Error {
 }
 
 // WithCode returns a copy of the error with the given code set.
+// A stack is captured if the code is listed in the [StackPolicy].
 func (e *Error) WithCode(c Code) *Error {
 	cp := *e
 	cp.code = c
+	cp.stack = codeStack(e, c)
 	return &cp
 }
 
@@ -115,6 +158,13 @@ func (e *Error) Unwrap() error {
 	return e.cause
 }
 
+// Is reports whether target is the [Template] this error was produced from,
+// so that errors.Is(err, tmpl) matches errors created by tmpl.New and tmpl.Wrap.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Template) //nolint:errorlint // templates match by identity
+	return ok && e.tmpl != nil && e.tmpl == t
+}
+
 // Code returns the code of this error.
 // If this error has no code set, it walks the cause chain.
 func (e *Error) Code() Code {
@@ -125,36 +175,70 @@ func (e *Error) Code() Code {
 }
 
 // Fields collects all structured fields from the error chain (outermost first).
+// The whole chain is walked, so fields of errx layers beneath foreign wrappers
+// (e.g. fmt.Errorf with %w) are included. Errors with multiple causes
+// (e.g. [Join] or [errors.Join]) are walked depth-first, so fields from every branch are included.
 // Duplicate keys are preserved, matching slog behavior.
 func Fields(err error) []slog.Attr {
 	var attrs []slog.Attr
-	for err != nil {
-		var ex *Error
-		if errors.As(err, &ex) {
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok { //nolint:errorlint // walk visits every layer
 			attrs = append(attrs, ex.fields...)
-			err = ex.cause
-		} else {
-			break
 		}
-	}
+		return true
+	})
 	return attrs
 }
 
 // DetailsOf collects all detail objects from the error chain (outermost first).
+// Like [Fields], it walks through foreign wrappers and into every branch.
 func DetailsOf(err error) []any {
 	var details []any
-	for err != nil {
-		var ex *Error
-		if errors.As(err, &ex) {
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok { //nolint:errorlint // walk visits every layer
 			details = append(details, ex.details...)
-			err = ex.cause
-		} else {
-			break
 		}
-	}
+		return true
+	})
 	return details
 }
 
+// walk calls fn for err and every error reachable from it through [unwrap],
+// in the depth-first pre-order used by [errors.Is] and [errors.As].
+// Foreign wrappers between errx layers are visited like any other layer.
+// The walk stops as soon as fn returns false; walk reports whether it ran to completion.
+func walk(err error, fn func(error) bool) bool {
+	for err != nil {
+		if !fn(err) {
+			return false
+		}
+		next, branches := unwrap(err)
+		for _, e := range branches {
+			if !walk(e, fn) {
+				return false
+			}
+		}
+		err = next
+	}
+	return true
+}
+
+// unwrap returns the direct cause of err, or its branches if err has multiple causes.
+// Besides Unwrap() error and Unwrap() []error it understands Cause() error,
+// which pkg/errors-style wrappers implement instead of (or in addition to) Unwrap.
+func unwrap(err error) (error, []error) {
+	switch x := err.(type) { //nolint:errorlint // unwrap inspects a single layer
+	case interface{ Unwrap() []error }:
+		return nil, x.Unwrap()
+	case interface{ Unwrap() error }:
+		return x.Unwrap(), nil
+	case interface{ Cause() error }:
+		return x.Cause(), nil
+	default:
+		return nil, nil
+	}
+}
+
 // Localizable is implemented by errors that can provide localized messages.
 // This interface lives in errx (not gerr) so that non-gRPC transports
 // (e.g. HTTP) can also leverage localized error messages.
@@ -2,6 +2,7 @@ package errx_test
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"testing"
 
@@ -76,6 +77,72 @@ func TestWrapf(t *testing.T) {
 	})
 }
 
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil when all nil", func(t *testing.T) {
+		t.Parallel()
+		if errx.Join(nil, nil) != nil {
+			t.Error("Join(nil, nil) should return nil")
+		}
+	})
+
+	t.Run("collects from every branch", func(t *testing.T) {
+		t.Parallel()
+		a := errx.New("name is required", "field", "name").
+			WithCode(errx.InvalidArgument).
+			WithFieldViolation("name", "required")
+		b := errx.New("email is invalid", "field", "email").
+			WithCode(errx.InvalidArgument).
+			WithFieldViolation("email", "invalid").
+			WithStack()
+		err := errx.Join(a, nil, b)
+
+		if err.Error() != "name is required\nemail is invalid" {
+			t.Errorf("Error() = %q", err.Error())
+		}
+		if !errors.Is(err, a) || !errors.Is(err, b) {
+			t.Error("errors.Is should match every branch")
+		}
+		if err.Code() != errx.InvalidArgument {
+			t.Errorf("Code() = %q, want %q", err.Code(), errx.InvalidArgument)
+		}
+
+		fields := errx.Fields(err)
+		if len(fields) != 2 || fields[0].Value.String() != "name" || fields[1].Value.String() != "email" {
+			t.Errorf("unexpected fields: %v", fields)
+		}
+		if details := errx.DetailsOf(err); len(details) != 2 {
+			t.Errorf("DetailsOf length = %d, want 2", len(details))
+		}
+		if errx.StackOf(err) == nil {
+			t.Error("StackOf should find the stack in the second branch")
+		}
+	})
+
+	t.Run("stdlib errors.Join", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Wrap(errors.Join(
+			errx.New("a", "k1", 1).WithDetails("d1"),
+			errx.New("b", "k2", 2).WithDetails("d2"),
+		), "k0", 0)
+
+		fields := errx.Fields(err)
+		if len(fields) != 3 {
+			t.Fatalf("Fields length = %d, want 3", len(fields))
+		}
+		for i, want := range []string{"k0", "k1", "k2"} {
+			if fields[i].Key != want {
+				t.Errorf("fields[%d].Key = %q, want %q", i, fields[i].Key, want)
+			}
+		}
+		details := errx.DetailsOf(err)
+		if len(details) != 2 || details[0] != "d1" || details[1] != "d2" {
+			t.Errorf("unexpected details: %v", details)
+		}
+	})
+}
+
 func TestWith(t *testing.T) {
 	t.Parallel()
 
@@ -220,6 +287,56 @@ func TestFields_Chain(t *testing.T) {
 	}
 }
 
+func TestFields_ForeignWrappers(t *testing.T) {
+	t.Parallel()
+
+	repo := errx.New("row missing", "table", "users").WithDetails("repo_detail")
+	svc := errx.Wrap(fmt.Errorf("find user: %w", repo), "user_id", 42).WithDetails("svc_detail")
+	handler := fmt.Errorf("handler: %w", errx.Wrap(fmt.Errorf("service: %w", svc), "route", "/users"))
+
+	fields := errx.Fields(handler)
+	if len(fields) != 3 {
+		t.Fatalf("Fields length = %d, want 3: %v", len(fields), fields)
+	}
+	for i, want := range []string{"route", "user_id", "table"} {
+		if fields[i].Key != want {
+			t.Errorf("fields[%d].Key = %q, want %q", i, fields[i].Key, want)
+		}
+	}
+
+	details := errx.DetailsOf(handler)
+	if len(details) != 2 || details[0] != "svc_detail" || details[1] != "repo_detail" {
+		t.Errorf("unexpected details: %v", details)
+	}
+}
+
+// causer mimics a pkg/errors-style wrapper that exposes Cause but not Unwrap.
+type causer struct {
+	msg   string
+	cause error
+}
+
+func (c *causer) Error() string { return c.msg + ": " + c.cause.Error() }
+func (c *causer) Cause() error  { return c.cause }
+
+func TestFields_CauseWrapper(t *testing.T) {
+	t.Parallel()
+
+	inner := errx.New("inner", "key", "val").WithCode(errx.NotFound).WithStack()
+	err := &causer{msg: "legacy", cause: inner}
+
+	fields := errx.Fields(err)
+	if len(fields) != 1 || fields[0].Key != "key" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if errx.CodeOf(err) != errx.NotFound {
+		t.Errorf("CodeOf() = %q, want %q", errx.CodeOf(err), errx.NotFound)
+	}
+	if errx.StackOf(err) == nil {
+		t.Error("StackOf should find the stack beneath the Cause wrapper")
+	}
+}
+
 func TestFields_NilError(t *testing.T) {
 	t.Parallel()
 
@@ -14,6 +14,7 @@ require (
 )
 
 require (
+	github.com/mickamy/errx/protodetail v0.0.6 // indirect
 	golang.org/x/net v0.50.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
 	golang.org/x/text v0.34.0 // indirect
@@ -25,4 +26,5 @@ replace (
 	github.com/mickamy/errx/cerr => ../cerr
 	github.com/mickamy/errx/gerr => ../gerr
 	github.com/mickamy/errx/herr => ../herr
+	github.com/mickamy/errx/protodetail => ../protodetail
 )
@@ -0,0 +1,129 @@
+package errx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+	"strconv"
+)
+
+// defaultFingerprintFrames is the number of stack frames that [Fingerprint]
+// takes into account when [FingerprintFrames] is not given.
+const defaultFingerprintFrames = 3
+
+// FingerprintOption configures [Fingerprint].
+type FingerprintOption func(*fingerprintConfig)
+
+type fingerprintConfig struct {
+	frames      int
+	ignoreLines bool
+	fieldValues bool
+}
+
+// IgnoreLines leaves the line numbers of stack frames out of the fingerprint,
+// so that it survives edits to the surrounding code.
+func IgnoreLines() FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.ignoreLines = true
+	}
+}
+
+// IncludeFieldValues takes field values into account, not only their keys.
+// Errors that differ in e.g. a user ID field then get different fingerprints.
+func IncludeFieldValues() FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.fieldValues = true
+	}
+}
+
+// FingerprintFrames sets the number of top stack frames taken into account. The default is 3;
+// zero or a negative n leaves the stack out.
+func FingerprintFrames(n int) FingerprintOption {
+	return func(c *fingerprintConfig) {
+		c.frames = max(n, 0)
+	}
+}
+
+// Fingerprint returns a stable key for grouping and deduplicating errors of the same kind,
+// or "" if err is nil. It is a hex-encoded hash of:
+//
+//   - the code and [Op] of every layer of the chain,
+//   - the message pattern of [Template] errors, the format string of [Wrapf] errors,
+//     the message and code of sentinels and of other errx layers, and the type of
+//     foreign errors (their messages often carry data),
+//   - the field keys of the chain, except those of fields taken from a context (see [NewCtx]),
+//   - the function names and line numbers of the top frames of the first stack in the chain.
+//
+// Use [IgnoreLines] to make the fingerprint coarser and [IncludeFieldValues] to make it finer.
+// Keep variable data in fields, templates or format arguments rather than in messages
+// built beforehand so that it does not split a group.
+func Fingerprint(err error, opts ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+	cfg := fingerprintConfig{frames: defaultFingerprintFrames}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	h := sha256.New()
+	walk(err, func(err error) bool {
+		switch x := err.(type) { //nolint:errorlint // walk visits every layer
+		case *Error:
+			switch {
+			case x.tmpl != nil:
+				writeFingerprint(h, "template", x.tmpl.pattern)
+			case x.format != "":
+				writeFingerprint(h, "format", x.format)
+			case x.msg != "":
+				writeFingerprint(h, "msg", x.msg)
+			}
+			if x.op != "" {
+				writeFingerprint(h, "op", string(x.op))
+			}
+			if x.code != "" {
+				writeFingerprint(h, "code", string(x.code))
+			}
+			for _, a := range x.fields {
+				switch {
+				case slices.Contains(x.ctxKeys, a.Key):
+				case cfg.fieldValues:
+					writeFingerprint(h, "field", a.Key, a.Value.Resolve().String())
+				default:
+					writeFingerprint(h, "field", a.Key)
+				}
+			}
+		case *SentinelError:
+			writeFingerprint(h, "sentinel", x.msg, string(x.code))
+		default:
+			writeFingerprint(h, "type", fmt.Sprintf("%T", err))
+			if c := resolveCode(err); c != "" {
+				writeFingerprint(h, "code", string(c))
+			}
+		}
+		return true
+	})
+
+	frames := StackOf(err).symbolized()
+	for _, f := range frames[:min(cfg.frames, len(frames))] {
+		if cfg.ignoreLines {
+			writeFingerprint(h, "frame", f.Function)
+		} else {
+			writeFingerprint(h, "frame", f.Function, strconv.Itoa(f.Line))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// writeFingerprint writes one length-prefixed component to h, so that adjacent
+// components cannot run into each other.
+func writeFingerprint(h hash.Hash, kind string, values ...string) {
+	h.Write([]byte(kind))
+	for _, v := range values {
+		h.Write([]byte(strconv.Itoa(len(v)) + ":" + v))
+	}
+	h.Write([]byte{0})
+}
@@ -0,0 +1,164 @@
+package errx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+var (
+	errFingerprintTmpl     = errx.Define(errx.NotFound, "user {user_id} not found")
+	errFingerprintSentinel = errx.NewSentinel("quota exceeded", errx.ResourceExhausted)
+)
+
+// stackedError creates an error whose stack starts in this function,
+// one frame above the line of its caller.
+func stackedError(msg string) *errx.Error {
+	return errx.New(msg).WithStack()
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	atLine1 := stackedError("fail")
+	atLine2 := stackedError("fail")
+	ctx1 := requestContext(t.Context())
+	ctx2 := context.WithValue(t.Context(), requestIDKey{}, "req-2")
+
+	tests := []struct {
+		name  string
+		a, b  error
+		opts  []errx.FingerprintOption
+		equal bool
+	}{
+		{
+			name:  "same template and fields",
+			a:     errFingerprintTmpl.New("user_id", 42),
+			b:     errFingerprintTmpl.New("user_id", 42),
+			equal: true,
+		},
+		{
+			name:  "field values ignored",
+			a:     errFingerprintTmpl.New("user_id", 42),
+			b:     errFingerprintTmpl.New("user_id", 43),
+			equal: true,
+		},
+		{
+			name:  "field values included",
+			a:     errFingerprintTmpl.New("user_id", 42),
+			b:     errFingerprintTmpl.New("user_id", 43),
+			opts:  []errx.FingerprintOption{errx.IncludeFieldValues()},
+			equal: false,
+		},
+		{
+			name:  "field keys differ",
+			a:     errFingerprintTmpl.New("user_id", 42),
+			b:     errFingerprintTmpl.New("id", 42),
+			equal: false,
+		},
+		{
+			name:  "context fields ignored",
+			a:     errx.NewCtx(ctx1, "fail", "user_id", 42),
+			b:     errx.NewCtx(ctx2, "fail", "user_id", 42),
+			opts:  []errx.FingerprintOption{errx.IncludeFieldValues()},
+			equal: true,
+		},
+		{
+			name:  "wrapped context fields ignored",
+			a:     errx.WrapCtx(ctx1, errFingerprintSentinel),
+			b:     errx.Wrap(errFingerprintSentinel).WithContext(ctx2),
+			opts:  []errx.FingerprintOption{errx.IncludeFieldValues()},
+			equal: true,
+		},
+		{
+			name:  "format arguments ignored",
+			a:     errx.Wrapf(errFingerprintSentinel, "load user %d", 42),
+			b:     errx.Wrapf(errFingerprintSentinel, "load user %d", 43),
+			equal: true,
+		},
+		{
+			name:  "formats differ",
+			a:     errx.Wrapf(errFingerprintSentinel, "load user %d", 42),
+			b:     errx.Wrapf(errFingerprintSentinel, "save user %d", 42),
+			equal: false,
+		},
+		{
+			name:  "codes differ",
+			a:     errx.New("fail").WithCode(errx.NotFound),
+			b:     errx.New("fail").WithCode(errx.Internal),
+			equal: false,
+		},
+		{
+			name:  "foreign messages ignored",
+			a:     errx.Wrap(fmt.Errorf("load user 42: %w", errFingerprintSentinel)),
+			b:     errx.Wrap(fmt.Errorf("load user 43: %w", errFingerprintSentinel)),
+			equal: true,
+		},
+		{
+			name:  "sentinels differ",
+			a:     errx.Wrap(errFingerprintSentinel),
+			b:     errx.Wrap(errx.NewSentinel("quota exceeded", errx.Unavailable)),
+			equal: false,
+		},
+		{
+			name:  "lines differ",
+			a:     atLine1,
+			b:     atLine2,
+			equal: false,
+		},
+		{
+			name:  "lines ignored",
+			a:     atLine1,
+			b:     atLine2,
+			opts:  []errx.FingerprintOption{errx.IgnoreLines()},
+			equal: true,
+		},
+		{
+			name:  "stack ignored",
+			a:     atLine1,
+			b:     errx.New("fail"),
+			opts:  []errx.FingerprintOption{errx.FingerprintFrames(0)},
+			equal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a, b := errx.Fingerprint(tt.a, tt.opts...), errx.Fingerprint(tt.b, tt.opts...)
+			if a == "" || b == "" {
+				t.Fatalf("Fingerprint() = %q, %q, want non-empty", a, b)
+			}
+			if (a == b) != tt.equal {
+				t.Errorf("Fingerprint() = %q, %q, want equal = %v", a, b, tt.equal)
+			}
+		})
+	}
+}
+
+func TestFingerprint_Nil(t *testing.T) {
+	t.Parallel()
+
+	if got := errx.Fingerprint(nil); got != "" {
+		t.Errorf("Fingerprint(nil) = %q, want empty", got)
+	}
+}
+
+func TestSlogAttr_WithFingerprint(t *testing.T) {
+	t.Parallel()
+
+	err := errx.Wrap(errors.New("boom"), "user_id", 42)
+
+	errObj := logSlogAttr(t, errx.SlogAttr(err, errx.WithFingerprint(errx.IncludeFieldValues())))
+	want := errx.Fingerprint(err, errx.IncludeFieldValues())
+	if got := errObj["fingerprint"]; got != want {
+		t.Errorf("fingerprint = %v, want %q", got, want)
+	}
+
+	if _, ok := logSlogAttr(t, errx.SlogAttr(err))["fingerprint"]; ok {
+		t.Error("fingerprint should be omitted without WithFingerprint")
+	}
+}
@@ -0,0 +1,142 @@
+package errx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compile-time checks
+var (
+	_ fmt.Formatter = (*Error)(nil)
+	_ fmt.Formatter = (*SentinelError)(nil)
+)
+
+// Format implements fmt.Formatter.
+//
+//	%s, %v  the error message (same as Error())
+//	%q      the quoted error message
+//	%+v     the error message and operation path, followed by every layer
+//	        of the cause chain with its op, code, fields, details and stack frames
+//
+// [Secret] values and fields with sensitive keys are redacted.
+func (e *Error) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e)
+}
+
+// Format implements fmt.Formatter. See [Error.Format] for the supported verbs.
+func (s *SentinelError) Format(st fmt.State, verb rune) {
+	formatError(st, verb, s)
+}
+
+func formatError(s fmt.State, verb rune, err error) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, verbose(err))
+			return
+		}
+		_, _ = io.WriteString(s, err.Error())
+	case 's':
+		_, _ = io.WriteString(s, err.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", err.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%T=%s)", verb, err, err.Error())
+	}
+}
+
+// verbose renders the multi-line %+v representation of err.
+func verbose(err error) string {
+	var b strings.Builder
+	b.WriteString(err.Error())
+	if ops := OpsOf(err); len(ops) > 0 {
+		b.WriteString("\n  ops: " + strings.Join(ops, " > "))
+	}
+	writeChain(&b, err, "  ", nil)
+	return b.String()
+}
+
+// writeChain writes every layer of the chain starting at err.
+// Branches of errors with multiple causes are written with deeper indentation.
+// enclosing is the nearest stack already written above err.
+func writeChain(b *strings.Builder, err error, indent string, enclosing *Stack) {
+	for err != nil {
+		b.WriteString("\n" + indent + "* ")
+		writeLayer(b, err, indent+"    ", enclosing)
+		if ex, ok := err.(*Error); ok && ex.stack != nil { //nolint:errorlint // inspecting a single layer
+			enclosing = ex.stack
+		}
+		next, branches := unwrap(err)
+		for _, e := range branches {
+			writeChain(b, e, indent+"  ", enclosing)
+		}
+		err = next
+	}
+}
+
+// writeLayer writes a single layer of the cause chain.
+// Only the data attached to this layer is written; causes are written separately.
+// Stack frames shared with the enclosing stack are omitted.
+func writeLayer(b *strings.Builder, err error, indent string, enclosing *Stack) {
+	switch v := err.(type) { //nolint:errorlint // inspecting a single layer, not the chain
+	case *Error:
+		if v.msg != "" {
+			b.WriteString(v.msg)
+		} else {
+			b.WriteString("(wrap)")
+		}
+		if v.op != "" {
+			b.WriteString("\n" + indent + "op: " + string(v.op))
+		}
+		if v.code != "" {
+			b.WriteString("\n" + indent + "code: " + v.code.String())
+		}
+		if v.publicMsg != "" {
+			b.WriteString("\n" + indent + "public: " + v.publicMsg)
+		}
+		if v.retry != 0 {
+			fmt.Fprintf(b, "\n"+indent+"retryable: %t", v.retry == Retryable)
+		}
+		if v.retryAfter > 0 {
+			b.WriteString("\n" + indent + "retry after: " + v.retryAfter.String())
+		}
+		if v.severity != 0 {
+			b.WriteString("\n" + indent + "severity: " + v.severity.String())
+		}
+		if len(v.fields) > 0 {
+			b.WriteString("\n" + indent + "fields:")
+			for _, a := range v.fields {
+				b.WriteString(" " + redactAttr(a).String())
+			}
+		}
+		if len(v.details) > 0 {
+			b.WriteString("\n" + indent + "details:")
+			for _, d := range v.details {
+				if ei, ok := d.(*ErrorInfoDetail); ok {
+					d = ei.Redacted()
+				}
+				fmt.Fprintf(b, "\n"+indent+"  - %T %+v", d, d)
+			}
+		}
+		if all := v.stack.symbolized(); len(all) > 0 {
+			frames := v.stack.Trim(enclosing)
+			b.WriteString("\n" + indent + "stack:")
+			for _, f := range frames {
+				fmt.Fprintf(b, "\n"+indent+"  %s\n"+indent+"      %s:%d", f.Function, f.File, f.Line)
+			}
+			if shared := len(all) - len(frames); shared > 0 {
+				fmt.Fprintf(b, "\n"+indent+"  ... %d frames shared with the enclosing stack", shared)
+			}
+		}
+	case *SentinelError:
+		b.WriteString(v.msg)
+		if v.code != "" {
+			b.WriteString("\n" + indent + "code: " + v.code.String())
+		}
+	case interface{ Unwrap() []error }:
+		fmt.Fprintf(b, "(%T)", err)
+	default:
+		fmt.Fprintf(b, "%s (%T)", err.Error(), err)
+	}
+}
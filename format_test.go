@@ -0,0 +1,124 @@
+package errx_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func TestError_Format(t *testing.T) {
+	t.Parallel()
+
+	err := errx.Wrapf(errors.New("connection refused"), "connect to %s", "db").
+		With("retry", 3).
+		WithCode(errx.Unavailable)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "connect to db: connection refused"},
+		{"%s", "connect to db: connection refused"},
+		{"%q", `"connect to db: connection refused"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+			if got := fmt.Sprintf(tt.format, err); got != tt.want {
+				t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_Format_Verbose(t *testing.T) {
+	t.Parallel()
+
+	inner := errx.New("user not found", "user_id", 42).
+		WithCode(errx.NotFound).
+		WithDetails(errx.FieldViolation("id", "unknown")).
+		WithStack()
+	outer := errx.Wrapf(inner, "get user")
+
+	got := fmt.Sprintf("%+v", outer)
+
+	lines := strings.Split(got, "\n")
+	if lines[0] != "get user: user not found" {
+		t.Errorf("first line = %q, want %q", lines[0], "get user: user not found")
+	}
+	for _, want := range []string{
+		"* get user",
+		"* user not found",
+		"code: not_found",
+		"fields: user_id=42",
+		"*errx.BadRequestDetail",
+		"stack:",
+		"TestError_Format_Verbose",
+		"format_test.go:",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%%+v output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "* get user") > strings.Index(got, "* user not found") {
+		t.Errorf("layers should be written outermost first:\n%s", got)
+	}
+}
+
+func TestError_Format_Verbose_SharedFrames(t *testing.T) {
+	t.Parallel()
+
+	inner := errx.New("inner").WithStack()
+	outer := errx.Wrap(inner).WithStack()
+
+	got := fmt.Sprintf("%+v", outer)
+	if !strings.Contains(got, "frames shared with the enclosing stack") {
+		t.Errorf("%%+v should trim frames shared with the enclosing stack:\n%s", got)
+	}
+}
+
+func TestError_Format_ForeignCause(t *testing.T) {
+	t.Parallel()
+
+	err := errx.Wrap(errors.New("root"))
+	got := fmt.Sprintf("%+v", err)
+	if !strings.Contains(got, "* (wrap)") {
+		t.Errorf("%%+v output missing wrap layer:\n%s", got)
+	}
+	if !strings.Contains(got, "* root (*errors.errorString)") {
+		t.Errorf("%%+v output missing foreign layer:\n%s", got)
+	}
+}
+
+func TestError_Format_Join(t *testing.T) {
+	t.Parallel()
+
+	err := errx.Join(
+		errx.New("a").WithCode(errx.InvalidArgument),
+		errx.New("b").WithCode(errx.NotFound),
+	)
+	got := fmt.Sprintf("%+v", err)
+	for _, want := range []string{
+		"\n    * a\n        code: invalid_argument",
+		"\n    * b\n        code: not_found",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%%+v output missing branch %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSentinel_Format(t *testing.T) {
+	t.Parallel()
+
+	s := errx.NewSentinel("not found", errx.NotFound)
+	if got := fmt.Sprintf("%v", s); got != "not found" {
+		t.Errorf("%%v = %q, want %q", got, "not found")
+	}
+	if got := fmt.Sprintf("%+v", s); !strings.Contains(got, "code: not_found") {
+		t.Errorf("%%+v output missing code:\n%s", got)
+	}
+}
@@ -4,54 +4,67 @@ import (
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/protoadapt"
 
 	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/protodetail"
 )
 
-// RegisterCode registers a custom mapping between an errx.Code and a gRPC codes.Code.
-// Both forward (errx → gRPC) and reverse (gRPC → errx) mappings are registered.
+// RegisterCode registers a custom mapping between an errx.Code and a gRPC codes.Code
+// in the default [Mapper]. See [Mapper.Register].
+// Prefer [errx.RegisterCode], which declares the mappings of every transport at once.
 // Must be called at program initialization (e.g. in init()), before serving requests.
 func RegisterCode(c errx.Code, gc codes.Code) {
-	errxToGRPC[c] = gc
-	grpcToErrx[gc] = c
+	defaultMapper.Register(c, gc)
 }
 
-// ToGRPCCode maps an errx.Code to a gRPC codes.Code.
-// Unknown or user-defined codes map to codes.Unknown.
+// ToGRPCCode maps an errx.Code to a gRPC codes.Code using the default [Mapper].
+// See [Mapper.ToGRPCCode].
 func ToGRPCCode(c errx.Code) codes.Code {
-	if gc, ok := errxToGRPC[c]; ok {
-		return gc
-	}
-	return codes.Unknown
+	return defaultMapper.ToGRPCCode(c)
 }
 
-// ToErrxCode maps a gRPC codes.Code to an errx.Code.
-// codes.OK maps to the zero value ("").
+// ToErrxCode maps a gRPC codes.Code to an errx.Code using the default [Mapper].
+// See [Mapper.ToErrxCode].
 func ToErrxCode(c codes.Code) errx.Code {
-	if ec, ok := grpcToErrx[c]; ok {
-		return ec
-	}
-	return errx.Unknown
+	return defaultMapper.ToErrxCode(c)
 }
 
-// ToStatus converts an error to a *status.Status.
-// If the error carries an errx.Code, it is mapped to a gRPC code.
-// The error message is used as the status message.
-// Any detail objects (proto.Message) attached via errx.WithDetails are
-// included as gRPC status details. Non-proto.Message details are ignored.
+// ToStatus converts an error to a *status.Status using the default [Mapper].
+// See [Mapper.ToStatus].
 func ToStatus(err error) *status.Status {
+	return defaultMapper.ToStatus(err)
+}
+
+// FromStatus converts a *status.Status to an *errx.Error using the default [Mapper].
+// See [Mapper.FromStatus].
+func FromStatus(st *status.Status) *errx.Error {
+	return defaultMapper.FromStatus(st)
+}
+
+// ToStatus converts an error to a *status.Status.
+// If the error carries an errx.Code, it is mapped to a gRPC code by m.
+// The public message (see errx.PublicMessage) is used as the status message.
+// Detail objects attached via errx.WithDetails (plus a RetryInfo for the hint set
+// with errx.WithRetryAfter, see protodetail.DetailsOf) are converted with protodetail.ToProto
+// and included as gRPC status details. Details no converter recognizes are dropped
+// and reported via errx.ReportUnknownDetail.
+func (m *Mapper) ToStatus(err error) *status.Status {
 	if err == nil {
 		return status.New(codes.OK, "")
 	}
 	c := errx.CodeOf(err)
-	st := status.New(ToGRPCCode(c), err.Error())
+	st := status.New(m.ToGRPCCode(c), errx.PublicMessage(err))
 
 	var protoDetails []protoadapt.MessageV1
-	for _, d := range errx.DetailsOf(err) {
-		if pm := toProtoDetail(d); pm != nil {
-			protoDetails = append(protoDetails, pm)
+	for _, d := range protodetail.DetailsOf(err) {
+		pm := protodetail.ToProto(d)
+		if pm == nil {
+			errx.ReportUnknownDetail("gerr", d)
+			continue
 		}
+		protoDetails = append(protoDetails, protoadapt.MessageV1Of(pm))
 	}
 	if len(protoDetails) > 0 {
 		if withDetails, detailErr := st.WithDetails(protoDetails...); detailErr == nil {
@@ -61,20 +74,35 @@ func ToStatus(err error) *status.Status {
 	return st
 }
 
-// FromStatus converts a *status.Status to an *errx.Error.
+// FromStatus converts a *status.Status to an *errx.Error, mapping its code with m.
 // Returns nil if the status code is OK.
-// Any gRPC status details are restored via errx.WithDetails.
-func FromStatus(st *status.Status) *errx.Error {
+// Any gRPC status details are restored via errx.WithDetails; messages with a
+// FromProto converter registered in errx are converted back to their detail type,
+// so google.rpc error details come back as the built-in errx details (e.g. *errx.BadRequestDetail).
+// A RetryInfo detail also restores the retry-after hint (see errx.RetryAfterOf).
+func (m *Mapper) FromStatus(st *status.Status) *errx.Error {
 	if st.Code() == codes.OK {
 		return nil
 	}
-	err := errx.New(st.Message()).WithCode(ToErrxCode(st.Code()))
-	if details := st.Details(); len(details) > 0 {
+	err := errx.New(st.Message()).WithCode(m.ToErrxCode(st.Code()))
+	var details []any
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok && ri.GetRetryDelay().AsDuration() > 0 {
+			err = err.WithRetryAfter(ri.GetRetryDelay().AsDuration())
+		}
+		if pm, ok := d.(proto.Message); ok {
+			d = protodetail.FromProto(pm)
+		}
+		details = append(details, d)
+	}
+	if len(details) > 0 {
 		err = err.WithDetails(details...)
 	}
 	return err
 }
 
+// errxToGRPC and grpcToErrx hold the built-in mappings. They are never modified;
+// custom mappings are registered in a [Mapper].
 var errxToGRPC = map[errx.Code]codes.Code{
 	errx.Canceled:           codes.Canceled,
 	errx.Unknown:            codes.Unknown,
@@ -94,51 +122,6 @@ var errxToGRPC = map[errx.Code]codes.Code{
 	errx.Unauthenticated:    codes.Unauthenticated,
 }
 
-// toProtoDetail converts an errx detail type to a proto.Message.
-// If the detail is already a proto.Message, it is returned as-is.
-// Returns nil for unrecognized types.
-func toProtoDetail(d any) protoadapt.MessageV1 {
-	switch v := d.(type) {
-	case *errx.BadRequestDetail:
-		violations := make([]*errdetails.BadRequest_FieldViolation, len(v.Violations))
-		for i, fv := range v.Violations {
-			violations[i] = &errdetails.BadRequest_FieldViolation{
-				Field:       fv.Field,
-				Description: fv.Description,
-			}
-		}
-		return &errdetails.BadRequest{FieldViolations: violations}
-	case *errx.PreconditionFailureDetail:
-		violations := make([]*errdetails.PreconditionFailure_Violation, len(v.Violations))
-		for i, pv := range v.Violations {
-			violations[i] = &errdetails.PreconditionFailure_Violation{
-				Type:        pv.Type,
-				Subject:     pv.Subject,
-				Description: pv.Description,
-			}
-		}
-		return &errdetails.PreconditionFailure{Violations: violations}
-	case *errx.ResourceInfoDetail:
-		return &errdetails.ResourceInfo{
-			ResourceType: v.ResourceType,
-			ResourceName: v.ResourceName,
-			Owner:        v.Owner,
-			Description:  v.Description,
-		}
-	case *errx.ErrorInfoDetail:
-		return &errdetails.ErrorInfo{
-			Reason:   v.Reason,
-			Domain:   v.Domain,
-			Metadata: v.Metadata,
-		}
-	default:
-		if pm, ok := d.(protoadapt.MessageV1); ok {
-			return pm
-		}
-		return nil
-	}
-}
-
 var grpcToErrx = map[codes.Code]errx.Code{
 	codes.OK:                 "",
 	codes.Canceled:           errx.Canceled,
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/mickamy/errx"
 	"github.com/mickamy/errx/gerr"
@@ -16,8 +18,35 @@ import (
 const testCustomCode errx.Code = "payment_required"
 const testCustomGRPC codes.Code = 100
 
+// testDeclaredCode is mapped through the errx code registry rather than RegisterCode.
+const testDeclaredCode errx.Code = "legal/blocked"
+
+// planDetail is a custom detail type registered with errx for tests.
+type planDetail struct {
+	Plan string `json:"plan"`
+}
+
+func registerPlanDetail() {
+	errx.RegisterDetail(errx.DetailType{
+		Name: "Plan",
+		New:  func() any { return &planDetail{} },
+		ToProto: func(d any) any {
+			return wrapperspb.String(d.(*planDetail).Plan) //nolint:forcetypeassert // registered type
+		},
+		FromProto: func(m any) (any, bool) {
+			sv, ok := m.(*wrapperspb.StringValue)
+			if !ok {
+				return nil, false
+			}
+			return &planDetail{Plan: sv.GetValue()}, true
+		},
+	})
+}
+
 func TestMain(m *testing.M) {
+	errx.RegisterCode(errx.CodeInfo{Code: testDeclaredCode, GRPCCode: 101})
 	gerr.RegisterCode(testCustomCode, testCustomGRPC)
+	registerPlanDetail()
 	os.Exit(m.Run())
 }
 
@@ -32,6 +61,20 @@ func TestRegisterCode(t *testing.T) {
 	}
 }
 
+func TestDeclaredCode(t *testing.T) {
+	t.Parallel()
+
+	if got := gerr.ToGRPCCode(testDeclaredCode); got != codes.Code(101) {
+		t.Errorf("ToGRPCCode(%q) = %v, want %v", testDeclaredCode, got, codes.Code(101))
+	}
+	if got := gerr.ToGRPCCode(testDeclaredCode + ".region"); got != codes.Code(101) {
+		t.Errorf("ToGRPCCode(%q) = %v, want %v", testDeclaredCode+".region", got, codes.Code(101))
+	}
+	if got := gerr.ToErrxCode(codes.Code(101)); got != testDeclaredCode {
+		t.Errorf("ToErrxCode(%v) = %q, want %q", codes.Code(101), got, testDeclaredCode)
+	}
+}
+
 func TestToGRPCCode(t *testing.T) {
 	t.Parallel()
 
@@ -48,6 +91,8 @@ func TestToGRPCCode(t *testing.T) {
 		{errx.PermissionDenied, codes.PermissionDenied},
 		{errx.Unauthenticated, codes.Unauthenticated},
 		{errx.Unavailable, codes.Unavailable},
+		{errx.Code("not_found.user"), codes.NotFound},
+		{errx.Code("unavailable.db.primary"), codes.Unavailable},
 		{errx.Code("custom"), codes.Unknown},
 		{errx.Code(""), codes.Unknown},
 	}
@@ -126,6 +171,28 @@ func TestToStatus(t *testing.T) {
 			t.Errorf("code = %v, want Internal", st.Code())
 		}
 	})
+
+	t.Run("public message", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Wrap(errors.New("pq: connection refused")).
+			WithCode(errx.Unavailable).
+			WithPublicMessage("service unavailable")
+		st := gerr.ToStatus(err)
+		if st.Message() != "service unavailable" {
+			t.Errorf("message = %q, want %q", st.Message(), "service unavailable")
+		}
+	})
+
+	t.Run("causes are not sent", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Wrapf(errors.New("pq: connection refused"), "query users").WithCode(errx.Unavailable)
+		if st := gerr.ToStatus(err); st.Message() != "query users" {
+			t.Errorf("message = %q, want %q", st.Message(), "query users")
+		}
+		if st := gerr.ToStatus(errors.New("pq: connection refused")); st.Message() != "internal error" {
+			t.Errorf("message = %q, want %q", st.Message(), "internal error")
+		}
+	})
 }
 
 func TestFromStatus(t *testing.T) {
@@ -203,6 +270,21 @@ func TestToStatus_WithDetails(t *testing.T) {
 			t.Fatalf("details length = %d, want 2", len(st.Details()))
 		}
 	})
+
+	t.Run("details from every joined branch", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Join(
+			errx.New("name").WithCode(errx.InvalidArgument).WithFieldViolation("name", "required"),
+			errx.New("email").WithCode(errx.InvalidArgument).WithFieldViolation("email", "invalid"),
+		)
+		st := gerr.ToStatus(err)
+		if st.Code() != codes.InvalidArgument {
+			t.Errorf("code = %v, want InvalidArgument", st.Code())
+		}
+		if len(st.Details()) != 2 {
+			t.Fatalf("details length = %d, want 2", len(st.Details()))
+		}
+	})
 }
 
 func TestFromStatus_WithDetails(t *testing.T) {
@@ -224,12 +306,12 @@ func TestFromStatus_WithDetails(t *testing.T) {
 		if len(details) != 1 {
 			t.Fatalf("details length = %d, want 1", len(details))
 		}
-		br, ok := details[0].(*errdetails.BadRequest)
+		br, ok := details[0].(*errx.BadRequestDetail)
 		if !ok {
-			t.Fatalf("detail type = %T, want *errdetails.BadRequest", details[0])
+			t.Fatalf("detail type = %T, want *errx.BadRequestDetail", details[0])
 		}
-		if br.GetFieldViolations()[0].GetField() != "email" {
-			t.Errorf("field = %q, want %q", br.GetFieldViolations()[0].GetField(), "email")
+		if br.Violations[0].Field != "email" {
+			t.Errorf("field = %q, want %q", br.Violations[0].Field, "email")
 		}
 	})
 
@@ -386,12 +468,76 @@ func TestRoundTrip_WithDetails(t *testing.T) {
 		t.Fatalf("details length = %d, want 2", len(details))
 	}
 
-	if _, ok := details[0].(*errdetails.BadRequest); !ok {
-		t.Errorf("detail[0] type = %T, want *errdetails.BadRequest", details[0])
+	if _, ok := details[0].(*errx.BadRequestDetail); !ok {
+		t.Errorf("detail[0] type = %T, want *errx.BadRequestDetail", details[0])
+	}
+	if lm, ok := details[1].(*errx.LocalizedMessageDetail); !ok {
+		t.Errorf("detail[1] type = %T, want *errx.LocalizedMessageDetail", details[1])
+	} else if lm.Message != "Email is invalid" {
+		t.Errorf("localized message = %q, want %q", lm.Message, "Email is invalid")
+	}
+}
+
+func TestRoundTrip_RegisteredDetail(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("upgrade needed").
+		WithCode(errx.FailedPrecondition).
+		WithDetails(&planDetail{Plan: "pro"})
+
+	st := gerr.ToStatus(original)
+	if len(st.Details()) != 1 {
+		t.Fatalf("details length = %d, want 1", len(st.Details()))
+	}
+	if _, ok := st.Details()[0].(*wrapperspb.StringValue); !ok {
+		t.Errorf("status detail type = %T, want *wrapperspb.StringValue", st.Details()[0])
+	}
+
+	details := errx.DetailsOf(gerr.FromStatus(st))
+	if len(details) != 1 {
+		t.Fatalf("details length = %d, want 1", len(details))
+	}
+	if pd, ok := details[0].(*planDetail); !ok || pd.Plan != "pro" {
+		t.Errorf("detail = %#v, want &planDetail{Plan: pro}", details[0])
+	}
+}
+
+func TestToStatus_ReportsUnknownDetail(t *testing.T) { //nolint:paralleltest // installs a global handler
+	var reported []any
+	errx.SetUnknownDetailHandler(func(transport string, detail any) {
+		if transport == "gerr" {
+			reported = append(reported, detail)
+		}
+	})
+	defer errx.SetUnknownDetailHandler(nil)
+
+	gerr.ToStatus(errx.New("fail").WithDetails("opaque", errx.FieldViolation("a", "b")))
+	if len(reported) != 1 || reported[0] != "opaque" {
+		t.Errorf("reported = %v, want [opaque]", reported)
+	}
+}
+
+func TestRoundTrip_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("busy").WithCode(errx.Unavailable).WithRetryAfter(3 * time.Second)
+
+	st := gerr.ToStatus(original)
+	var found bool
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			found = ri.GetRetryDelay().AsDuration() == 3*time.Second
+		}
+	}
+	if !found {
+		t.Fatalf("status details = %v, want RetryInfo of 3s", st.Details())
+	}
+
+	recovered := gerr.FromStatus(st)
+	if d, ok := errx.RetryAfterOf(recovered); !ok || d != 3*time.Second {
+		t.Errorf("RetryAfterOf() = %v, %v, want 3s, true", d, ok)
 	}
-	if lm, ok := details[1].(*errdetails.LocalizedMessage); !ok {
-		t.Errorf("detail[1] type = %T, want *errdetails.LocalizedMessage", details[1])
-	} else if lm.GetMessage() != "Email is invalid" {
-		t.Errorf("localized message = %q, want %q", lm.GetMessage(), "Email is invalid")
+	if !errx.IsRetryable(recovered) {
+		t.Error("recovered error should be retryable")
 	}
 }
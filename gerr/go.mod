@@ -3,7 +3,8 @@ module github.com/mickamy/errx/gerr
 go 1.25.0
 
 require (
-	github.com/mickamy/errx v0.0.5
+	github.com/mickamy/errx v0.0.6
+	github.com/mickamy/errx/protodetail v0.0.6
 	golang.org/x/text v0.34.0
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260217215200-42d3e9bedb6d
 	google.golang.org/grpc v1.79.1
@@ -3,6 +3,7 @@ package gerr
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"golang.org/x/text/language"
 	"google.golang.org/grpc"
@@ -17,6 +18,9 @@ type InterceptorOption func(*interceptorConfig)
 type interceptorConfig struct {
 	localeFunc    func(context.Context) string
 	defaultLocale language.Tag
+	recovery      bool
+	mapper        *Mapper
+	logger        *slog.Logger
 }
 
 // WithLocaleFunc sets a custom function to extract locale from context.
@@ -39,9 +43,37 @@ func WithDefaultLocale(tag language.Tag) InterceptorOption {
 	}
 }
 
+// WithRecovery makes the interceptors recover panics in handlers. The panic value
+// is converted with errx.FromPanic (code Internal, stack at the panic site) and
+// returned to the client like any other error.
+func WithRecovery() InterceptorOption {
+	return func(cfg *interceptorConfig) {
+		cfg.recovery = true
+	}
+}
+
+// WithLogger makes the interceptors log every error a handler returns (or panic they
+// recover) with errx.Log, at the level of errx.LevelOf, together with the full method name.
+func WithLogger(l *slog.Logger) InterceptorOption {
+	return func(cfg *interceptorConfig) {
+		cfg.logger = l
+	}
+}
+
+// WithMapper sets the [Mapper] used to convert errors. The default is [DefaultMapper].
+func WithMapper(m *Mapper) InterceptorOption {
+	return func(cfg *interceptorConfig) {
+		if m == nil {
+			return
+		}
+		cfg.mapper = m
+	}
+}
+
 func newInterceptorConfig(opts []InterceptorOption) *interceptorConfig {
 	cfg := &interceptorConfig{
 		localeFunc: defaultLocaleFunc,
+		mapper:     defaultMapper,
 	}
 	for _, o := range opts {
 		o(cfg)
@@ -63,7 +95,7 @@ func defaultLocaleFunc(ctx context.Context) string {
 }
 
 // UnaryServerInterceptor returns a gRPC unary server interceptor that
-// converts returned errors to gRPC status errors using ToStatus.
+// converts returned errors to gRPC status errors using [Mapper.ToStatus].
 // If the error implements errx.Localizable, a LocalizedMessage detail
 // is automatically appended.
 func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
@@ -71,19 +103,26 @@ func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerIntercept
 	return func(
 		ctx context.Context,
 		req any,
-		_ *grpc.UnaryServerInfo,
+		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
-	) (any, error) {
-		resp, err := handler(ctx, req)
+	) (resp any, err error) {
+		if cfg.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					resp, err = nil, cfg.toStatusError(ctx, info.FullMethod, errx.FromPanic(r))
+				}
+			}()
+		}
+		resp, err = handler(ctx, req)
 		if err != nil {
-			return nil, cfg.toStatusError(ctx, err)
+			return nil, cfg.toStatusError(ctx, info.FullMethod, err)
 		}
 		return resp, nil
 	}
 }
 
 // StreamServerInterceptor returns a gRPC stream server interceptor that
-// converts returned errors to gRPC status errors using ToStatus.
+// converts returned errors to gRPC status errors using [Mapper.ToStatus].
 // If the error implements errx.Localizable, a LocalizedMessage detail
 // is automatically appended.
 func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
@@ -91,12 +130,18 @@ func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterce
 	return func(
 		srv any,
 		ss grpc.ServerStream,
-		_ *grpc.StreamServerInfo,
+		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
-	) error {
-		err := handler(srv, ss)
-		if err != nil {
-			return cfg.toStatusError(ss.Context(), err)
+	) (err error) {
+		if cfg.recovery {
+			defer func() {
+				if r := recover(); r != nil {
+					err = cfg.toStatusError(ss.Context(), info.FullMethod, errx.FromPanic(r))
+				}
+			}()
+		}
+		if err = handler(srv, ss); err != nil {
+			return cfg.toStatusError(ss.Context(), info.FullMethod, err)
 		}
 		return nil
 	}
@@ -104,9 +149,13 @@ func StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterce
 
 // toStatusError converts an error to a gRPC status error, automatically
 // appending a LocalizedMessage detail if the error implements errx.Localizable.
-func (cfg *interceptorConfig) toStatusError(ctx context.Context, err error) error {
+// The error is logged first if a logger is configured.
+func (cfg *interceptorConfig) toStatusError(ctx context.Context, method string, err error) error {
+	if cfg.logger != nil {
+		errx.Log(ctx, cfg.logger, err, "rpc failed", "method", method)
+	}
 	err = appendLocalizedDetail(ctx, err, cfg.localeFunc, cfg.defaultLocale)
-	return ToStatus(err).Err() //nolint:wrapcheck // intentionally returns gRPC status error
+	return cfg.mapper.ToStatus(err).Err() //nolint:wrapcheck // intentionally returns gRPC status error
 }
 
 // appendLocalizedDetail checks if the error (or any error in its chain)
@@ -132,7 +181,7 @@ func appendLocalizedDetail(
 	}
 	var ex *errx.Error
 	if errors.As(err, &ex) {
-		return ex.WithDetails(LocalizedMessage(locale, msg))
+		return ex.WithDetails(errx.LocalizedMessage(locale, msg))
 	}
-	return errx.Wrap(err).WithDetails(LocalizedMessage(locale, msg))
+	return errx.Wrap(err).WithDetails(errx.LocalizedMessage(locale, msg))
 }
@@ -1,8 +1,12 @@
 package gerr_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"testing"
 
 	"golang.org/x/text/language"
@@ -73,6 +77,19 @@ func TestUnaryServerInterceptor(t *testing.T) {
 			t.Errorf("code = %v, want Unknown", st.Code())
 		}
 	})
+
+	t.Run("context error", func(t *testing.T) {
+		t.Parallel()
+		_, err := interceptor(
+			t.Context(), "req", &grpc.UnaryServerInfo{},
+			func(_ context.Context, _ any) (any, error) {
+				return nil, fmt.Errorf("query: %w", context.Canceled)
+			},
+		)
+		if got := status.Code(err); got != codes.Canceled {
+			t.Errorf("code = %v, want Canceled", got)
+		}
+	})
 }
 
 // fakeServerStream is a minimal grpc.ServerStream for testing.
@@ -346,3 +363,79 @@ func TestStreamServerInterceptor_Localizable(t *testing.T) {
 
 // Ensure localizableError implements errx.Localizable at compile time.
 var _ errx.Localizable = (*localizableError)(nil)
+
+func TestServerInterceptors_WithRecovery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unary", func(t *testing.T) {
+		t.Parallel()
+		interceptor := gerr.UnaryServerInterceptor(gerr.WithRecovery())
+		resp, err := interceptor(
+			t.Context(), "req", &grpc.UnaryServerInfo{},
+			func(_ context.Context, _ any) (any, error) {
+				panic("db password=secret")
+			},
+		)
+		if resp != nil {
+			t.Errorf("resp should be nil, got %v", resp)
+		}
+		st, ok := status.FromError(err)
+		if !ok {
+			t.Fatal("error should be a gRPC status error")
+		}
+		if st.Code() != codes.Internal {
+			t.Errorf("code = %v, want Internal", st.Code())
+		}
+		if st.Message() != "internal error" {
+			t.Errorf("message = %q, want %q (the panic value must not be sent)", st.Message(), "internal error")
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		t.Parallel()
+		interceptor := gerr.StreamServerInterceptor(gerr.WithRecovery())
+		ss := &fakeServerStream{ctx: t.Context()}
+		err := interceptor(
+			nil, ss, &grpc.StreamServerInfo{},
+			func(_ any, _ grpc.ServerStream) error {
+				panic(errors.New("boom"))
+			},
+		)
+		st, ok := status.FromError(err)
+		if !ok {
+			t.Fatal("error should be a gRPC status error")
+		}
+		if st.Code() != codes.Internal {
+			t.Errorf("code = %v, want Internal", st.Code())
+		}
+		if st.Message() != "internal error" {
+			t.Errorf("message = %q, want %q (the panic value must not be sent)", st.Message(), "internal error")
+		}
+	})
+}
+
+func TestUnaryServerInterceptor_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	interceptor := gerr.UnaryServerInterceptor(gerr.WithLogger(logger))
+
+	_, _ = interceptor(
+		t.Context(), "req", &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetUser"},
+		func(_ context.Context, _ any) (any, error) {
+			return nil, errx.New("db down").WithCode(errx.Unavailable)
+		},
+	)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nbody: %s", err, buf.String())
+	}
+	if m["level"] != "ERROR" {
+		t.Errorf("level = %v, want ERROR", m["level"])
+	}
+	if m["method"] != "/user.v1.UserService/GetUser" {
+		t.Errorf("method = %v", m["method"])
+	}
+}
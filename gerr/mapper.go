@@ -0,0 +1,98 @@
+package gerr
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/mickamy/errx"
+)
+
+// Mapper maps errx codes to gRPC codes and back.
+// It starts from the built-in mappings and those declared with [errx.RegisterCode];
+// [Mapper.Register] adds custom mappings that only this Mapper uses, so that servers
+// in one binary (e.g. a public and an internal API) can map codes differently.
+// The zero value is ready to use. A Mapper is safe for concurrent use
+// and must not be copied after first use.
+type Mapper struct {
+	mu     sync.RWMutex
+	toGRPC map[errx.Code]codes.Code
+	toErrx map[codes.Code]errx.Code
+}
+
+// defaultMapper is used by the package-level functions and by interceptors without [WithMapper].
+var defaultMapper = NewMapper()
+
+// NewMapper returns a Mapper with only the built-in and registry mappings.
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// DefaultMapper returns the Mapper used by the package-level functions
+// and by interceptors without [WithMapper].
+func DefaultMapper() *Mapper {
+	return defaultMapper
+}
+
+// Register registers a custom mapping between an errx.Code and a gRPC codes.Code.
+// Both forward (errx → gRPC) and reverse (gRPC → errx) mappings are registered,
+// overriding the built-in ones.
+// It panics if c is malformed (see [errx.ValidateCode]).
+func (m *Mapper) Register(c errx.Code, gc codes.Code) {
+	if err := errx.ValidateCode(c); err != nil {
+		panic(err.Error())
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.toGRPC == nil {
+		m.toGRPC = map[errx.Code]codes.Code{}
+		m.toErrx = map[codes.Code]errx.Code{}
+	}
+	m.toGRPC[c] = gc
+	m.toErrx[gc] = c
+}
+
+// ToGRPCCode maps an errx.Code to a gRPC codes.Code.
+// A code is mapped by [Mapper.Register], else by the GRPCCode of its [errx.CodeInfo]
+// (which may override a built-in mapping), else by the built-in mappings.
+// A code without a mapping of its own maps like its nearest mapped ancestor
+// (see [errx.Code.Parent]); codes with none map to codes.Unknown.
+func (m *Mapper) ToGRPCCode(c errx.Code) codes.Code {
+	for ; c != ""; c = c.Parent() {
+		m.mu.RLock()
+		gc, ok := m.toGRPC[c]
+		m.mu.RUnlock()
+		if ok {
+			return gc
+		}
+		if info, ok := errx.LookupCode(c); ok && info.GRPCCode != 0 {
+			return codes.Code(info.GRPCCode)
+		}
+		if gc, ok := errxToGRPC[c]; ok {
+			return gc
+		}
+	}
+	return codes.Unknown
+}
+
+// ToErrxCode maps a gRPC codes.Code to an errx.Code.
+// codes.OK maps to the zero value ("").
+// Codes declared with a matching GRPCCode in [errx.CodeInfo] are used for values
+// without a registered or built-in mapping; the rest map to errx.Unknown.
+func (m *Mapper) ToErrxCode(gc codes.Code) errx.Code {
+	m.mu.RLock()
+	c, ok := m.toErrx[gc]
+	m.mu.RUnlock()
+	if ok {
+		return c
+	}
+	if c, ok := grpcToErrx[gc]; ok {
+		return c
+	}
+	for _, info := range errx.Codes() {
+		if info.GRPCCode == uint32(gc) {
+			return info.Code
+		}
+	}
+	return errx.Unknown
+}
@@ -0,0 +1,109 @@
+package gerr_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/gerr"
+)
+
+const testAdminCode errx.Code = "admin/locked"
+
+func TestToGRPCCode_RegistryOverridesBuiltin(t *testing.T) { //nolint:paralleltest // declares a global code mapping
+	orig, _ := errx.LookupCode(errx.NotFound)
+	errx.RegisterCode(errx.CodeInfo{Code: errx.NotFound, GRPCCode: uint32(codes.Unavailable)})
+	t.Cleanup(func() {
+		orig.GRPCCode = uint32(codes.NotFound)
+		errx.RegisterCode(orig)
+	})
+
+	if got := gerr.ToGRPCCode(errx.NotFound); got != codes.Unavailable {
+		t.Errorf("ToGRPCCode(%q) = %v, want %v", errx.NotFound, got, codes.Unavailable)
+	}
+	if got := gerr.ToGRPCCode("not_found.user"); got != codes.Unavailable {
+		t.Errorf("ToGRPCCode(%q) = %v, want %v", "not_found.user", got, codes.Unavailable)
+	}
+}
+
+func TestMapper_Register(t *testing.T) {
+	t.Parallel()
+
+	public := gerr.NewMapper()
+	admin := gerr.NewMapper()
+	admin.Register(testAdminCode, codes.FailedPrecondition)
+	admin.Register("not_found.user", codes.PermissionDenied)
+
+	tests := []struct {
+		name   string
+		mapper *gerr.Mapper
+		code   errx.Code
+		want   codes.Code
+	}{
+		{"registered", admin, testAdminCode, codes.FailedPrecondition},
+		{"registered child", admin, testAdminCode + ".account", codes.FailedPrecondition},
+		{"overrides parent", admin, "not_found.user", codes.PermissionDenied},
+		{"built-in", admin, errx.NotFound, codes.NotFound},
+		{"other mapper", public, testAdminCode, codes.Unknown},
+		{"other mapper parent", public, "not_found.user", codes.NotFound},
+		{"default mapper", gerr.DefaultMapper(), testAdminCode, codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.mapper.ToGRPCCode(tt.code); got != tt.want {
+				t.Errorf("ToGRPCCode(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	if got := admin.ToErrxCode(codes.FailedPrecondition); got != testAdminCode {
+		t.Errorf("ToErrxCode(FailedPrecondition) = %q, want %q", got, testAdminCode)
+	}
+	if got := public.ToErrxCode(codes.FailedPrecondition); got != errx.FailedPrecondition {
+		t.Errorf("ToErrxCode(FailedPrecondition) = %q, want %q", got, errx.FailedPrecondition)
+	}
+}
+
+func TestMapper_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	var m gerr.Mapper
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			m.Register(testAdminCode, codes.Aborted)
+			_ = m.ToGRPCCode(testAdminCode)
+			_ = m.ToStatus(errx.New("locked").WithCode(testAdminCode))
+		})
+	}
+	wg.Wait()
+
+	if got := m.ToGRPCCode(testAdminCode); got != codes.Aborted {
+		t.Errorf("ToGRPCCode(%q) = %v, want %v", testAdminCode, got, codes.Aborted)
+	}
+}
+
+func TestUnaryServerInterceptor_WithMapper(t *testing.T) {
+	t.Parallel()
+
+	m := gerr.NewMapper()
+	m.Register(testAdminCode, codes.Aborted)
+	interceptor := gerr.UnaryServerInterceptor(gerr.WithMapper(m))
+
+	_, err := interceptor(
+		t.Context(), "req", &grpc.UnaryServerInfo{},
+		func(_ context.Context, _ any) (any, error) {
+			return nil, errx.New("locked").WithCode(testAdminCode)
+		},
+	)
+	if got := status.Code(err); got != codes.Aborted {
+		t.Errorf("code = %v, want Aborted", got)
+	}
+}
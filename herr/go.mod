@@ -3,6 +3,6 @@ module github.com/mickamy/errx/herr
 go 1.25.0
 
 require (
-	github.com/mickamy/errx v0.0.5
+	github.com/mickamy/errx v0.0.6
 	golang.org/x/text v0.34.0
 )
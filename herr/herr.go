@@ -2,40 +2,37 @@ package herr
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/mickamy/errx"
 )
 
-// RegisterCode registers a custom mapping between an errx.Code and an HTTP status code.
-// Both forward (errx → HTTP) and reverse (HTTP → errx) mappings are registered.
+// RegisterCode registers a custom mapping between an errx.Code and an HTTP status code
+// in the default [Mapper]. See [Mapper.Register].
+// Prefer [errx.RegisterCode], which declares the mappings of every transport at once.
 // Must be called at program initialization (e.g. in init()), before serving requests.
 func RegisterCode(c errx.Code, status int) {
-	errxToHTTP[c] = status
-	httpToErrx[status] = c
+	defaultMapper.Register(c, status)
 }
 
-// ToHTTPStatus maps an errx.Code to an HTTP status code.
-// Unknown or user-defined codes map to 500.
+// ToHTTPStatus maps an errx.Code to an HTTP status code using the default [Mapper].
+// See [Mapper.ToHTTPStatus].
 func ToHTTPStatus(c errx.Code) int {
-	if s, ok := errxToHTTP[c]; ok {
-		return s
-	}
-	return http.StatusInternalServerError
+	return defaultMapper.ToHTTPStatus(c)
 }
 
-// ToErrxCode maps an HTTP status code to an errx.Code.
-// Unmapped status codes return errx.Unknown.
+// ToErrxCode maps an HTTP status code to an errx.Code using the default [Mapper].
+// See [Mapper.ToErrxCode].
 func ToErrxCode(status int) errx.Code {
-	if c, ok := httpToErrx[status]; ok {
-		return c
-	}
-	return errx.Unknown
+	return defaultMapper.ToErrxCode(status)
 }
 
 // ProblemDetail is an RFC 9457 Problem Details response.
 // Standard members (type, title, status, detail, instance) follow the spec.
-// Extension members (code, errors, localized_message) carry errx-specific data.
+// Extension members (code, errors, help, localized_message, fingerprint) carry errx-specific data.
 type ProblemDetail struct {
 	// RFC 9457 standard members.
 	Type     string `json:"type"`
@@ -47,7 +44,12 @@ type ProblemDetail struct {
 	// Extension members.
 	Code             string           `json:"code,omitempty"`
 	Errors           []map[string]any `json:"errors,omitempty"`
+	Help             []errx.HelpLink  `json:"help,omitempty"`
 	LocalizedMessage *LocalizedMsg    `json:"localized_message,omitempty"`
+
+	// Fingerprint is the [errx.Fingerprint] of the error, set by the middleware
+	// when [WithFingerprint] is given.
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
 
 // LocalizedMsg holds a locale-specific error message.
@@ -74,14 +76,25 @@ func WithType(typeURI string) ProblemDetailOption {
 	}
 }
 
-// ToProblemDetail converts an error to an RFC 9457 [ProblemDetail].
-// Returns nil if err is nil.
+// ToProblemDetail converts an error to an RFC 9457 [ProblemDetail] using the default [Mapper].
+// See [Mapper.ToProblemDetail].
 func ToProblemDetail(err error, opts ...ProblemDetailOption) *ProblemDetail {
+	return defaultMapper.ToProblemDetail(err, opts...)
+}
+
+// ToProblemDetail converts an error to an RFC 9457 [ProblemDetail],
+// mapping its code to the status with m.
+// The detail member is the public message of err (see [errx.PublicMessage]).
+// Links of [errx.HelpDetail] details are rendered in the help member, other details whose
+// type is registered with [errx.RegisterDetail] in the errors member; the rest are dropped
+// and reported via [errx.ReportUnknownDetail].
+// Returns nil if err is nil.
+func (m *Mapper) ToProblemDetail(err error, opts ...ProblemDetailOption) *ProblemDetail {
 	if err == nil {
 		return nil
 	}
 	c := errx.CodeOf(err)
-	status := ToHTTPStatus(c)
+	status := m.statusOf(err, c)
 
 	code := string(c)
 	if code == "" {
@@ -97,14 +110,21 @@ func ToProblemDetail(err error, opts ...ProblemDetailOption) *ProblemDetail {
 		Type:   "about:blank",
 		Title:  title,
 		Status: status,
-		Detail: err.Error(),
+		Detail: errx.PublicMessage(err),
 		Code:   code,
 	}
 
 	for _, d := range errx.DetailsOf(err) {
-		if m := toDetailJSON(d); m != nil {
-			p.Errors = append(p.Errors, m)
+		if h, ok := d.(*errx.HelpDetail); ok {
+			p.Help = append(p.Help, h.Links...)
+			continue
+		}
+		obj := toDetailJSON(d)
+		if obj == nil {
+			errx.ReportUnknownDetail("herr", d)
+			continue
 		}
+		p.Errors = append(p.Errors, obj)
 	}
 
 	for _, o := range opts {
@@ -114,26 +134,82 @@ func ToProblemDetail(err error, opts ...ProblemDetailOption) *ProblemDetail {
 	return p
 }
 
+// FromProblemDetail converts an RFC 9457 [ProblemDetail] back to an [*errx.Error]
+// using the default [Mapper]. See [Mapper.FromProblemDetail].
+func FromProblemDetail(p *ProblemDetail) *errx.Error {
+	return defaultMapper.FromProblemDetail(p)
+}
+
 // FromProblemDetail converts an RFC 9457 [ProblemDetail] back to an [*errx.Error].
+// Without a code member, the code is mapped from the status with m.
+// Entries of the errors member whose type is registered with [errx.RegisterDetail]
+// are restored as details, and the help member as an [errx.HelpDetail].
 // Returns nil if p is nil.
-func FromProblemDetail(p *ProblemDetail) *errx.Error {
+func (m *Mapper) FromProblemDetail(p *ProblemDetail) *errx.Error {
 	if p == nil {
 		return nil
 	}
 	code := errx.Code(p.Code)
 	if code == "" {
-		code = ToErrxCode(p.Status)
+		code = m.ToErrxCode(p.Status)
 	}
-	return errx.New(p.Detail).WithCode(code)
+	err := errx.New(p.Detail).WithCode(code)
+	var details []any
+	for _, obj := range p.Errors {
+		if d := fromDetailJSON(obj); d != nil {
+			details = append(details, d)
+		}
+	}
+	if len(p.Help) > 0 {
+		details = append(details, errx.Help(p.Help...))
+	}
+	if len(details) > 0 {
+		err = err.WithDetails(details...)
+	}
+	return err
 }
 
-// WriteError writes an RFC 9457 JSON error response to w.
-// Does nothing if err is nil.
+// WriteError writes an RFC 9457 JSON error response to w using the default [Mapper].
+// See [Mapper.WriteError].
 func WriteError(w http.ResponseWriter, err error, opts ...ProblemDetailOption) {
+	defaultMapper.WriteError(w, err, opts...)
+}
+
+// WriteError writes an RFC 9457 JSON error response to w (see [Mapper.ToProblemDetail]).
+// The retry-after hint of err (see [errx.RetryAfterOf]) is sent as a Retry-After header.
+// Does nothing if err is nil.
+func (m *Mapper) WriteError(w http.ResponseWriter, err error, opts ...ProblemDetailOption) {
 	if err == nil {
 		return
 	}
-	writeProblemDetail(w, ToProblemDetail(err, opts...))
+	setRetryAfter(w.Header(), err)
+	writeProblemDetail(w, m.ToProblemDetail(err, opts...))
+}
+
+// setRetryAfter sets the Retry-After header (in seconds, rounded up)
+// from the retry-after hint of err, if any.
+func setRetryAfter(h http.Header, err error) {
+	if d, ok := errx.RetryAfterOf(err); ok {
+		h.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10))
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header in either of its forms
+// (delay in seconds or HTTP date) into the duration to wait.
+// It reports false if the header is missing or invalid.
+func ParseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(t), 0), true
 }
 
 func writeProblemDetail(w http.ResponseWriter, p *ProblemDetail) {
@@ -150,53 +226,46 @@ func writeProblemDetail(w http.ResponseWriter, p *ProblemDetail) {
 	_, _ = w.Write([]byte("\n"))
 }
 
+// toDetailJSON converts a detail registered with errx.RegisterDetail to a JSON object
+// tagged with its type name. Returns nil for details that are not registered.
 func toDetailJSON(d any) map[string]any {
-	switch v := d.(type) {
-	case *errx.BadRequestDetail:
-		violations := make([]map[string]any, len(v.Violations))
-		for i, fv := range v.Violations {
-			violations[i] = map[string]any{
-				"field":       fv.Field,
-				"description": fv.Description,
-			}
-		}
-		return map[string]any{
-			"type":       "BadRequest",
-			"violations": violations,
-		}
-	case *errx.PreconditionFailureDetail:
-		violations := make([]map[string]any, len(v.Violations))
-		for i, pv := range v.Violations {
-			violations[i] = map[string]any{
-				"type":        pv.Type,
-				"subject":     pv.Subject,
-				"description": pv.Description,
-			}
-		}
-		return map[string]any{
-			"type":       "PreconditionFailure",
-			"violations": violations,
-		}
-	case *errx.ResourceInfoDetail:
-		return map[string]any{
-			"type":          "ResourceInfo",
-			"resource_type": v.ResourceType,
-			"resource_name": v.ResourceName,
-			"owner":         v.Owner,
-			"description":   v.Description,
-		}
-	case *errx.ErrorInfoDetail:
-		return map[string]any{
-			"type":     "ErrorInfo",
-			"reason":   v.Reason,
-			"domain":   v.Domain,
-			"metadata": v.Metadata,
-		}
-	default:
+	dt, ok := errx.LookupDetail(d)
+	if !ok {
+		return nil
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil || m == nil {
+		return nil
+	}
+	m["type"] = dt.Name
+	return m
+}
+
+// fromDetailJSON restores a detail from a JSON object produced by toDetailJSON.
+// Returns nil if the type is not registered or the object does not decode.
+func fromDetailJSON(m map[string]any) any {
+	name, _ := m["type"].(string)
+	dt, ok := errx.LookupDetailName(name)
+	if !ok {
+		return nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	d := dt.New()
+	if err := json.Unmarshal(b, d); err != nil {
 		return nil
 	}
+	return d
 }
 
+// errxToHTTP and httpToErrx hold the built-in mappings. They are never modified;
+// custom mappings are registered in a [Mapper].
 var errxToHTTP = map[errx.Code]int{
 	errx.InvalidArgument:    http.StatusBadRequest,
 	errx.OutOfRange:         http.StatusBadRequest,
@@ -217,16 +286,17 @@ var errxToHTTP = map[errx.Code]int{
 }
 
 var httpToErrx = map[int]errx.Code{
-	http.StatusBadRequest:          errx.InvalidArgument,
-	http.StatusUnauthorized:        errx.Unauthenticated,
-	http.StatusForbidden:           errx.PermissionDenied,
-	http.StatusNotFound:            errx.NotFound,
-	http.StatusConflict:            errx.AlreadyExists,
-	http.StatusPreconditionFailed:  errx.FailedPrecondition,
-	http.StatusTooManyRequests:     errx.ResourceExhausted,
-	499:                            errx.Canceled,
-	http.StatusInternalServerError: errx.Internal,
-	http.StatusNotImplemented:      errx.Unimplemented,
-	http.StatusServiceUnavailable:  errx.Unavailable,
-	http.StatusGatewayTimeout:      errx.DeadlineExceeded,
+	http.StatusBadRequest:            errx.InvalidArgument,
+	http.StatusUnauthorized:          errx.Unauthenticated,
+	http.StatusForbidden:             errx.PermissionDenied,
+	http.StatusNotFound:              errx.NotFound,
+	http.StatusConflict:              errx.AlreadyExists,
+	http.StatusPreconditionFailed:    errx.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: errx.OutOfRange,
+	http.StatusTooManyRequests:       errx.ResourceExhausted,
+	499:                              errx.Canceled,
+	http.StatusInternalServerError:   errx.Internal,
+	http.StatusNotImplemented:        errx.Unimplemented,
+	http.StatusServiceUnavailable:    errx.Unavailable,
+	http.StatusGatewayTimeout:        errx.DeadlineExceeded,
 }
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/mickamy/errx"
 	"github.com/mickamy/errx/herr"
@@ -14,8 +16,18 @@ import (
 
 const testCustomCode errx.Code = "payment_required"
 
+// testDeclaredCode is mapped through the errx code registry rather than RegisterCode.
+const testDeclaredCode errx.Code = "legal/blocked"
+
+// planDetail is a custom detail type registered with errx for tests.
+type planDetail struct {
+	Plan string `json:"plan"`
+}
+
 func TestMain(m *testing.M) {
+	errx.RegisterCode(errx.CodeInfo{Code: testDeclaredCode, HTTPStatus: http.StatusUnavailableForLegalReasons})
 	herr.RegisterCode(testCustomCode, http.StatusPaymentRequired)
+	errx.RegisterDetail(errx.DetailType{Name: "Plan", New: func() any { return &planDetail{} }})
 	os.Exit(m.Run())
 }
 
@@ -30,6 +42,20 @@ func TestRegisterCode(t *testing.T) {
 	}
 }
 
+func TestDeclaredCode(t *testing.T) {
+	t.Parallel()
+
+	if got := herr.ToHTTPStatus(testDeclaredCode); got != http.StatusUnavailableForLegalReasons {
+		t.Errorf("ToHTTPStatus(%q) = %v, want %v", testDeclaredCode, got, http.StatusUnavailableForLegalReasons)
+	}
+	if got := herr.ToHTTPStatus(testDeclaredCode + ".region"); got != http.StatusUnavailableForLegalReasons {
+		t.Errorf("ToHTTPStatus(%q) = %v, want %v", testDeclaredCode+".region", got, http.StatusUnavailableForLegalReasons)
+	}
+	if got := herr.ToErrxCode(http.StatusUnavailableForLegalReasons); got != testDeclaredCode {
+		t.Errorf("ToErrxCode(%v) = %q, want %q", http.StatusUnavailableForLegalReasons, got, testDeclaredCode)
+	}
+}
+
 func TestToHTTPStatus(t *testing.T) {
 	t.Parallel()
 
@@ -53,6 +79,8 @@ func TestToHTTPStatus(t *testing.T) {
 		{errx.Unimplemented, http.StatusNotImplemented},
 		{errx.Unavailable, http.StatusServiceUnavailable},
 		{errx.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errx.Code("not_found.user"), http.StatusNotFound},
+		{errx.Code(string(testCustomCode) + ".card"), http.StatusPaymentRequired},
 		{errx.Code("custom"), http.StatusInternalServerError},
 		{errx.Code(""), http.StatusInternalServerError},
 	}
@@ -80,6 +108,7 @@ func TestToErrxCode(t *testing.T) {
 		{http.StatusNotFound, errx.NotFound},
 		{http.StatusConflict, errx.AlreadyExists},
 		{http.StatusPreconditionFailed, errx.FailedPrecondition},
+		{http.StatusRequestEntityTooLarge, errx.OutOfRange},
 		{http.StatusTooManyRequests, errx.ResourceExhausted},
 		{499, errx.Canceled},
 		{http.StatusInternalServerError, errx.Internal},
@@ -130,6 +159,28 @@ func TestToProblemDetail(t *testing.T) {
 		}
 	})
 
+	t.Run("public message", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Wrap(errors.New("pq: connection refused")).
+			WithCode(errx.Unavailable).
+			WithPublicMessage("service unavailable")
+		p := herr.ToProblemDetail(err)
+		if p.Detail != "service unavailable" {
+			t.Errorf("Detail = %q, want %q", p.Detail, "service unavailable")
+		}
+	})
+
+	t.Run("causes are not sent", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Wrapf(errors.New("pq: connection refused"), "query users").WithCode(errx.Unavailable)
+		if p := herr.ToProblemDetail(err); p.Detail != "query users" {
+			t.Errorf("Detail = %q, want %q", p.Detail, "query users")
+		}
+		if p := herr.ToProblemDetail(errors.New("pq: connection refused")); p.Detail != "internal error" {
+			t.Errorf("Detail = %q, want %q", p.Detail, "internal error")
+		}
+	})
+
 	t.Run("non-standard status code falls back to code string for title", func(t *testing.T) {
 		t.Parallel()
 		err := errx.New("client closed").WithCode(errx.Canceled)
@@ -236,6 +287,21 @@ func TestToProblemDetail(t *testing.T) {
 		}
 	})
 
+	t.Run("details from every joined branch", func(t *testing.T) {
+		t.Parallel()
+		err := errx.Join(
+			errx.New("name").WithCode(errx.InvalidArgument).WithFieldViolation("name", "required"),
+			errx.New("email").WithCode(errx.InvalidArgument).WithFieldViolation("email", "invalid"),
+		)
+		p := herr.ToProblemDetail(err)
+		if p.Status != http.StatusBadRequest {
+			t.Errorf("Status = %d, want %d", p.Status, http.StatusBadRequest)
+		}
+		if len(p.Errors) != 2 {
+			t.Fatalf("errors length = %d, want 2", len(p.Errors))
+		}
+	})
+
 	t.Run("non-errx details are ignored", func(t *testing.T) {
 		t.Parallel()
 		err := errx.New("fail").
@@ -263,6 +329,24 @@ func TestWriteError(t *testing.T) {
 		}
 	})
 
+	t.Run("retry-after hint sets the header", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		herr.WriteError(w, errx.New("busy").WithCode(errx.Unavailable).WithRetryAfter(1500*time.Millisecond))
+		if got := w.Header().Get("Retry-After"); got != "2" {
+			t.Errorf("Retry-After = %q, want %q", got, "2")
+		}
+	})
+
+	t.Run("no hint, no header", func(t *testing.T) {
+		t.Parallel()
+		w := httptest.NewRecorder()
+		herr.WriteError(w, errx.New("busy").WithCode(errx.Unavailable))
+		if got := w.Header().Get("Retry-After"); got != "" {
+			t.Errorf("Retry-After = %q, want empty", got)
+		}
+	})
+
 	t.Run("errx error", func(t *testing.T) {
 		t.Parallel()
 		err := errx.New("user not found").WithCode(errx.NotFound)
@@ -392,3 +476,148 @@ func TestFromProblemDetail(t *testing.T) {
 		}
 	})
 }
+
+func TestRoundTrip_RegisteredDetail(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("upgrade needed").
+		WithCode(errx.FailedPrecondition).
+		WithDetails(&planDetail{Plan: "pro"}, errx.FieldViolation("plan", "required"))
+
+	w := httptest.NewRecorder()
+	herr.WriteError(w, original)
+
+	var p herr.ProblemDetail
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Errors) != 2 {
+		t.Fatalf("errors length = %d, want 2", len(p.Errors))
+	}
+	if p.Errors[0]["type"] != "Plan" || p.Errors[0]["plan"] != "pro" {
+		t.Errorf("errors[0] = %v, want {type: Plan, plan: pro}", p.Errors[0])
+	}
+
+	details := errx.DetailsOf(herr.FromProblemDetail(&p))
+	if len(details) != 2 {
+		t.Fatalf("details length = %d, want 2", len(details))
+	}
+	if pd, ok := details[0].(*planDetail); !ok || pd.Plan != "pro" {
+		t.Errorf("details[0] = %#v, want &planDetail{Plan: pro}", details[0])
+	}
+	if br, ok := details[1].(*errx.BadRequestDetail); !ok || br.Violations[0].Field != "plan" {
+		t.Errorf("details[1] = %#v, want BadRequestDetail for plan", details[1])
+	}
+}
+
+func TestRoundTrip_HelpAndNativeDetails(t *testing.T) {
+	t.Parallel()
+
+	original := errx.New("rate limited").
+		WithCode(errx.ResourceExhausted).
+		WithDetails(
+			errx.QuotaFailure(errx.QuotaViolation{Subject: "project:abc", Description: "RPM limit exceeded"}),
+			errx.RetryInfo(5*time.Second),
+			errx.Help(errx.HelpLink{Description: "Quota docs", URL: "https://example.com/quota"}),
+		)
+
+	w := httptest.NewRecorder()
+	herr.WriteError(w, original)
+
+	var raw map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	help, ok := raw["help"].([]any)
+	if !ok || len(help) != 1 {
+		t.Fatalf("help member = %v, want one link", raw["help"])
+	}
+	if link, _ := help[0].(map[string]any); link["url"] != "https://example.com/quota" {
+		t.Errorf("help[0] = %v, want url https://example.com/quota", help[0])
+	}
+
+	var p herr.ProblemDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Errors) != 2 {
+		t.Fatalf("errors length = %d, want 2 (help is not an errors entry)", len(p.Errors))
+	}
+	if p.Errors[0]["type"] != "QuotaFailure" {
+		t.Errorf("errors[0].type = %v, want QuotaFailure", p.Errors[0]["type"])
+	}
+	if p.Errors[1]["type"] != "RetryInfo" || p.Errors[1]["retry_delay"] != "5s" {
+		t.Errorf("errors[1] = %v, want {type: RetryInfo, retry_delay: 5s}", p.Errors[1])
+	}
+
+	details := errx.DetailsOf(herr.FromProblemDetail(&p))
+	if !reflect.DeepEqual(details, errx.DetailsOf(original)) {
+		t.Errorf("DetailsOf() = %#v, want %#v", details, errx.DetailsOf(original))
+	}
+}
+
+func TestToProblemDetail_ReportsUnknownDetail(t *testing.T) { //nolint:paralleltest // installs a global handler
+	var reported []any
+	errx.SetUnknownDetailHandler(func(transport string, detail any) {
+		if transport == "herr" {
+			reported = append(reported, detail)
+		}
+	})
+	defer errx.SetUnknownDetailHandler(nil)
+
+	herr.ToProblemDetail(errx.New("fail").WithDetails("opaque", errx.FieldViolation("a", "b")))
+	if len(reported) != 1 || reported[0] != "opaque" {
+		t.Errorf("reported = %v, want [opaque]", reported)
+	}
+}
+
+func TestToProblemDetail_RedactsErrorInfo(t *testing.T) { //nolint:paralleltest // registers a global sensitive key
+	err := errx.New("login failed").WithDetails(errx.ErrorInfo("LOGIN_FAILED", "example.com", map[string]string{
+		"x-herr-token": "tok-1",
+	}))
+	errx.RegisterSensitiveKeys("x-herr-token")
+
+	p := herr.ToProblemDetail(err)
+	if len(p.Errors) != 1 {
+		t.Fatalf("Errors length = %d, want 1", len(p.Errors))
+	}
+	metadata, _ := p.Errors[0]["metadata"].(map[string]any)
+	if metadata["x-herr-token"] != "[REDACTED]" {
+		t.Errorf("metadata = %v, want the token redacted", metadata)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "120", 2 * time.Minute, true},
+		{"past date", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"invalid", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := http.Header{}
+			if tt.value != "" {
+				h.Set("Retry-After", tt.value)
+			}
+			got, ok := herr.ParseRetryAfter(h)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	h := http.Header{}
+	h.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if got, ok := herr.ParseRetryAfter(h); !ok || got <= 58*time.Minute {
+		t.Errorf("ParseRetryAfter(future date) = %v, %v, want about 1h", got, ok)
+	}
+}
@@ -0,0 +1,107 @@
+package herr
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/mickamy/errx"
+)
+
+// Mapper maps errx codes to HTTP status codes and back.
+// It starts from the built-in mappings and those declared with [errx.RegisterCode];
+// [Mapper.Register] adds custom mappings that only this Mapper uses, so that servers
+// in one binary (e.g. a public and an internal API) can map codes differently.
+// The zero value is ready to use. A Mapper is safe for concurrent use
+// and must not be copied after first use.
+type Mapper struct {
+	mu     sync.RWMutex
+	toHTTP map[errx.Code]int
+	toErrx map[int]errx.Code
+}
+
+// defaultMapper is used by the package-level functions and by handlers without [WithMapper].
+var defaultMapper = NewMapper()
+
+// NewMapper returns a Mapper with only the built-in and registry mappings.
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// DefaultMapper returns the Mapper used by the package-level functions
+// and by handlers without [WithMapper].
+func DefaultMapper() *Mapper {
+	return defaultMapper
+}
+
+// Register registers a custom mapping between an errx.Code and an HTTP status code.
+// Both forward (errx → HTTP) and reverse (HTTP → errx) mappings are registered,
+// overriding the built-in ones.
+// It panics if c is malformed (see [errx.ValidateCode]).
+func (m *Mapper) Register(c errx.Code, status int) {
+	if err := errx.ValidateCode(c); err != nil {
+		panic(err.Error())
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.toHTTP == nil {
+		m.toHTTP = map[errx.Code]int{}
+		m.toErrx = map[int]errx.Code{}
+	}
+	m.toHTTP[c] = status
+	m.toErrx[status] = c
+}
+
+// ToHTTPStatus maps an errx.Code to an HTTP status code.
+// A code is mapped by [Mapper.Register], else by the HTTPStatus of its [errx.CodeInfo]
+// (which may override a built-in mapping), else by the built-in mappings.
+// A code without a mapping of its own maps like its nearest mapped ancestor
+// (see [errx.Code.Parent]); codes with none map to 500.
+func (m *Mapper) ToHTTPStatus(c errx.Code) int {
+	for ; c != ""; c = c.Parent() {
+		m.mu.RLock()
+		s, ok := m.toHTTP[c]
+		m.mu.RUnlock()
+		if ok {
+			return s
+		}
+		if info, ok := errx.LookupCode(c); ok && info.HTTPStatus != 0 {
+			return info.HTTPStatus
+		}
+		if s, ok := errxToHTTP[c]; ok {
+			return s
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// ToErrxCode maps an HTTP status code to an errx.Code.
+// Codes declared with a matching HTTPStatus in [errx.CodeInfo] are used for values
+// without a registered or built-in mapping; the rest map to errx.Unknown.
+func (m *Mapper) ToErrxCode(status int) errx.Code {
+	m.mu.RLock()
+	c, ok := m.toErrx[status]
+	m.mu.RUnlock()
+	if ok {
+		return c
+	}
+	if c, ok := httpToErrx[status]; ok {
+		return c
+	}
+	for _, info := range errx.Codes() {
+		if info.HTTPStatus == status {
+			return info.Code
+		}
+	}
+	return errx.Unknown
+}
+
+// statusOf returns the HTTP status for err with code c. A request body that exceeded
+// [http.MaxBytesReader]'s limit is reported as 413 rather than the 400 of OutOfRange.
+func (m *Mapper) statusOf(err error, c errx.Code) int {
+	var mbe *http.MaxBytesError
+	if c.Is(errx.OutOfRange) && errors.As(err, &mbe) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return m.ToHTTPStatus(c)
+}
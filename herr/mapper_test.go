@@ -0,0 +1,108 @@
+package herr_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/herr"
+)
+
+const testAdminCode errx.Code = "admin/locked"
+
+func TestToHTTPStatus_RegistryOverridesBuiltin(t *testing.T) { //nolint:paralleltest // declares a global code mapping
+	orig, _ := errx.LookupCode(errx.NotFound)
+	errx.RegisterCode(errx.CodeInfo{Code: errx.NotFound, HTTPStatus: http.StatusGone})
+	t.Cleanup(func() {
+		orig.HTTPStatus = http.StatusNotFound
+		errx.RegisterCode(orig)
+	})
+
+	if got := herr.ToHTTPStatus(errx.NotFound); got != http.StatusGone {
+		t.Errorf("ToHTTPStatus(%q) = %v, want %v", errx.NotFound, got, http.StatusGone)
+	}
+	if got := herr.ToHTTPStatus("not_found.user"); got != http.StatusGone {
+		t.Errorf("ToHTTPStatus(%q) = %v, want %v", "not_found.user", got, http.StatusGone)
+	}
+	if got := herr.ToErrxCode(http.StatusGone); got != errx.NotFound {
+		t.Errorf("ToErrxCode(%v) = %q, want %q", http.StatusGone, got, errx.NotFound)
+	}
+}
+
+func TestMapper_Register(t *testing.T) {
+	t.Parallel()
+
+	public := herr.NewMapper()
+	admin := herr.NewMapper()
+	admin.Register(testAdminCode, http.StatusLocked)
+	admin.Register("not_found.user", http.StatusForbidden)
+
+	tests := []struct {
+		name   string
+		mapper *herr.Mapper
+		code   errx.Code
+		want   int
+	}{
+		{"registered", admin, testAdminCode, http.StatusLocked},
+		{"registered child", admin, testAdminCode + ".account", http.StatusLocked},
+		{"overrides parent", admin, "not_found.user", http.StatusForbidden},
+		{"built-in", admin, errx.NotFound, http.StatusNotFound},
+		{"other mapper", public, testAdminCode, http.StatusInternalServerError},
+		{"other mapper parent", public, "not_found.user", http.StatusNotFound},
+		{"default mapper", herr.DefaultMapper(), testAdminCode, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.mapper.ToHTTPStatus(tt.code); got != tt.want {
+				t.Errorf("ToHTTPStatus(%q) = %d, want %d", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	if got := admin.ToErrxCode(http.StatusLocked); got != testAdminCode {
+		t.Errorf("ToErrxCode(423) = %q, want %q", got, testAdminCode)
+	}
+	if got := public.ToErrxCode(http.StatusLocked); got != errx.Unknown {
+		t.Errorf("ToErrxCode(423) = %q, want %q", got, errx.Unknown)
+	}
+}
+
+func TestMapper_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	var m herr.Mapper
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			m.Register(testAdminCode, http.StatusLocked)
+			_ = m.ToHTTPStatus(testAdminCode)
+			_ = m.ToProblemDetail(errx.New("locked").WithCode(testAdminCode))
+		})
+	}
+	wg.Wait()
+
+	if got := m.ToHTTPStatus(testAdminCode); got != http.StatusLocked {
+		t.Errorf("ToHTTPStatus(%q) = %d, want %d", testAdminCode, got, http.StatusLocked)
+	}
+}
+
+func TestHandler_WithMapper(t *testing.T) {
+	t.Parallel()
+
+	m := herr.NewMapper()
+	m.Register(testAdminCode, http.StatusLocked)
+	h := herr.Handler(func(_ http.ResponseWriter, _ *http.Request) error {
+		return errx.New("locked").WithCode(testAdminCode)
+	}, herr.WithMapper(m))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusLocked {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusLocked)
+	}
+}
@@ -2,6 +2,7 @@ package herr
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"golang.org/x/text/language"
@@ -15,6 +16,10 @@ type MiddlewareOption func(*middlewareConfig)
 type middlewareConfig struct {
 	localeFunc    func(http.Header) string
 	defaultLocale language.Tag
+	recovery      bool
+	mapper        *Mapper
+	logger        *slog.Logger
+	fingerprint   []errx.FingerprintOption // nil unless WithFingerprint is given
 }
 
 // WithLocaleFunc sets a custom function to extract locale from request headers.
@@ -37,9 +42,47 @@ func WithDefaultLocale(tag language.Tag) MiddlewareOption {
 	}
 }
 
+// WithRecovery makes the handler recover panics. The panic value is converted
+// with [errx.FromPanic] (code Internal, stack at the panic site) and written as a
+// problem detail like any other error. [http.ErrAbortHandler] is re-panicked so
+// that net/http aborts the response as intended.
+func WithRecovery() MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.recovery = true
+	}
+}
+
+// WithLogger makes the handler log every error it writes (including recovered panics)
+// with [errx.Log], at the level of [errx.LevelOf], together with the request method and path.
+func WithLogger(l *slog.Logger) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.logger = l
+	}
+}
+
+// WithFingerprint adds the [errx.Fingerprint] of the error, computed with the given options,
+// to the problem detail as the "fingerprint" extension member. Clients can quote it
+// when reporting a problem so that it is matched with the logged error.
+func WithFingerprint(opts ...errx.FingerprintOption) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.fingerprint = append([]errx.FingerprintOption{}, opts...)
+	}
+}
+
+// WithMapper sets the [Mapper] used to convert errors. The default is [DefaultMapper].
+func WithMapper(m *Mapper) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		if m == nil {
+			return
+		}
+		cfg.mapper = m
+	}
+}
+
 func newMiddlewareConfig(opts []MiddlewareOption) *middlewareConfig {
 	cfg := &middlewareConfig{
 		localeFunc: defaultLocaleFunc,
+		mapper:     defaultMapper,
 	}
 	for _, o := range opts {
 		o(cfg)
@@ -62,18 +105,34 @@ type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
 func Handler(h HandlerFunc, opts ...MiddlewareOption) http.Handler {
 	cfg := newMiddlewareConfig(opts)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.recovery {
+			defer func() {
+				if rec := recover(); rec != nil {
+					if rec == http.ErrAbortHandler {
+						panic(rec)
+					}
+					cfg.writeErrorWithLocale(w, r, errx.FromPanic(rec))
+				}
+			}()
+		}
 		if err := h(w, r); err != nil {
-			cfg.writeErrorWithLocale(w, r.Header, err)
+			cfg.writeErrorWithLocale(w, r, err)
 		}
 	})
 }
 
-func (cfg *middlewareConfig) writeErrorWithLocale(w http.ResponseWriter, header http.Header, err error) {
-	p := ToProblemDetail(err)
+func (cfg *middlewareConfig) writeErrorWithLocale(w http.ResponseWriter, r *http.Request, err error) {
+	if cfg.logger != nil {
+		errx.Log(r.Context(), cfg.logger, err, "request failed", "method", r.Method, "path", r.URL.Path)
+	}
+	p := cfg.mapper.ToProblemDetail(err)
+	if cfg.fingerprint != nil {
+		p.Fingerprint = errx.Fingerprint(err, cfg.fingerprint...)
+	}
 
 	var l errx.Localizable
 	if errors.As(err, &l) {
-		locale := cfg.localeFunc(header)
+		locale := cfg.localeFunc(r.Header)
 		if locale == "" && cfg.defaultLocale != language.Und {
 			locale = cfg.defaultLocale.String()
 		}
@@ -84,5 +143,6 @@ func (cfg *middlewareConfig) writeErrorWithLocale(w http.ResponseWriter, header
 		}
 	}
 
+	setRetryAfter(w.Header(), err)
 	writeProblemDetail(w, p)
 }
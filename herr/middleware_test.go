@@ -1,11 +1,18 @@
 package herr_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/text/language"
 
@@ -62,6 +69,24 @@ func TestHandler_ErrxError(t *testing.T) {
 	}
 }
 
+func TestHandler_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	h := herr.Handler(func(_ http.ResponseWriter, _ *http.Request) error {
+		return errx.New("rate limited").WithCode(errx.ResourceExhausted).WithRetryAfter(30 * time.Second)
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want %q", got, "30")
+	}
+}
+
 func TestHandler_WithDetails(t *testing.T) {
 	t.Parallel()
 
@@ -277,3 +302,156 @@ func TestHandler_WithLocaleFunc(t *testing.T) {
 		t.Errorf("locale = %q, want %q", p.LocalizedMessage.Locale, "ja-JP")
 	}
 }
+
+func TestHandler_WithRecovery(t *testing.T) {
+	t.Parallel()
+
+	h := herr.Handler(func(_ http.ResponseWriter, _ *http.Request) error {
+		panic("db password=secret")
+	}, herr.WithRecovery())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var p herr.ProblemDetail
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Code != "internal" {
+		t.Errorf("Code = %q, want %q", p.Code, "internal")
+	}
+	if p.Detail != "internal error" {
+		t.Errorf("Detail = %q, want %q (the panic value must not be sent)", p.Detail, "internal error")
+	}
+}
+
+func TestHandler_WithRecovery_AbortHandler(t *testing.T) {
+	t.Parallel()
+
+	h := herr.Handler(func(_ http.ResponseWriter, _ *http.Request) error {
+		panic(http.ErrAbortHandler)
+	}, herr.WithRecovery())
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestHandler_ForeignErrors(t *testing.T) {
+	t.Parallel()
+
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name       string
+		handler    herr.HandlerFunc
+		want       int
+		wantDetail string
+	}{
+		{
+			name: "missing file",
+			handler: func(_ http.ResponseWriter, _ *http.Request) error {
+				_, err := os.Open(missing)
+				return err
+			},
+			want:       http.StatusNotFound,
+			wantDetail: "A requested entity was not found.",
+		},
+		{
+			name: "request body too large",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				_, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4))
+				return err
+			},
+			want:       http.StatusRequestEntityTooLarge,
+			wantDetail: "The operation was attempted past the valid range.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("too large"))
+			herr.Handler(tt.handler).ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			var p herr.ProblemDetail
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Detail != tt.wantDetail {
+				t.Errorf("Detail = %q, want %q (a client error)", p.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestHandler_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := herr.Handler(func(_ http.ResponseWriter, _ *http.Request) error {
+		return errx.New("user not found").WithCode(errx.NotFound)
+	}, herr.WithLogger(logger))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nbody: %s", err, buf.String())
+	}
+	if m["level"] != "INFO" {
+		t.Errorf("level = %v, want INFO", m["level"])
+	}
+	if m["path"] != "/users/1" {
+		t.Errorf("path = %v, want /users/1", m["path"])
+	}
+}
+
+func TestHandler_WithFingerprint(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := errx.New("user not found", "user_id", 42).WithCode(errx.NotFound)
+	handler := func(_ http.ResponseWriter, _ *http.Request) error { return errNotFound }
+
+	tests := []struct {
+		name string
+		opts []herr.MiddlewareOption
+		want string
+	}{
+		{
+			name: "enabled",
+			opts: []herr.MiddlewareOption{herr.WithFingerprint(errx.IncludeFieldValues())},
+			want: errx.Fingerprint(errNotFound, errx.IncludeFieldValues()),
+		},
+		{
+			name: "disabled",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := httptest.NewRecorder()
+			herr.Handler(handler, tt.opts...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+			var p herr.ProblemDetail
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("failed to parse JSON: %v", err)
+			}
+			if p.Fingerprint != tt.want {
+				t.Errorf("fingerprint = %q, want %q", p.Fingerprint, tt.want)
+			}
+		})
+	}
+}
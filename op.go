@@ -0,0 +1,48 @@
+package errx
+
+// Op names the operation an error passed through, e.g. "UserRepo.Find" or "HTTP GET /users/{id}".
+// Recording an op on each layer ([Error.WithOp], [Annotate]) gives a trail of the calls
+// an error crossed, which is much cheaper than capturing a stack.
+type Op string
+
+// WithOp returns a copy of the error with the operation set.
+//
+//	return errx.Wrap(err).WithOp("UserRepo.Find")
+func (e *Error) WithOp(op Op) *Error {
+	cp := *e
+	cp.op = op
+	return &cp
+}
+
+// OpsOf returns the operations recorded on the error chain, outermost first,
+// e.g. ["HTTP GET /users/{id}", "UserService.Get", "UserRepo.Find"].
+// Errors with multiple causes are walked depth-first.
+func OpsOf(err error) []string {
+	var ops []string
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok && ex.op != "" { //nolint:errorlint // walk visits every layer
+			ops = append(ops, string(ex.op))
+		}
+		return true
+	})
+	return ops
+}
+
+// Annotate wraps the error errp points to with the operation and optional structured fields,
+// if it is non-nil. It is meant to be deferred with a named error result:
+//
+//	func (r *UserRepo) Find(ctx context.Context, id int) (_ *User, err error) {
+//	    defer errx.Annotate(&err, "UserRepo.Find", "user_id", id)
+//	    ...
+//	}
+func Annotate(errp *error, op Op, args ...any) {
+	if errp == nil || *errp == nil {
+		return
+	}
+	*errp = &Error{
+		cause:  *errp,
+		op:     op,
+		fields: argsToAttrs(args),
+		stack:  autoStack(*errp, ""),
+	}
+}
@@ -0,0 +1,119 @@
+package errx_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func findUser(fail bool) (err error) {
+	defer errx.Annotate(&err, "UserRepo.Find", "user_id", 42)
+	if fail {
+		return errx.New("no rows").WithCode(errx.NotFound)
+	}
+	return nil
+}
+
+func getUser(fail bool) (err error) {
+	defer errx.Annotate(&err, "UserService.Get")
+	return findUser(fail)
+}
+
+func TestOpsOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "annotated",
+			err:  errx.Wrap(getUser(true)).WithOp("HTTP GET /users/{id}"),
+			want: []string{"HTTP GET /users/{id}", "UserService.Get", "UserRepo.Find"},
+		},
+		{
+			name: "through foreign wrapper",
+			err:  fmt.Errorf("handler: %w", errx.New("boom").WithOp("Worker.Run")),
+			want: []string{"Worker.Run"},
+		},
+		{
+			name: "joined",
+			err: errx.Join(
+				errx.New("a").WithOp("A"),
+				errx.New("b").WithOp("B"),
+			).WithOp("Batch"),
+			want: []string{"Batch", "A", "B"},
+		},
+		{
+			name: "no ops",
+			err:  errx.New("boom"),
+			want: nil,
+		},
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errx.OpsOf(tt.err); !slices.Equal(got, tt.want) {
+				t.Errorf("OpsOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	if err := getUser(false); err != nil {
+		t.Errorf("getUser() = %v, want nil", err)
+	}
+
+	err := getUser(true)
+	if got := err.Error(); got != "no rows" {
+		t.Errorf("Error() = %q, want %q", got, "no rows")
+	}
+	if !errx.IsCode(err, errx.NotFound) {
+		t.Errorf("CodeOf() = %q, want %q", errx.CodeOf(err), errx.NotFound)
+	}
+	fields := errx.Fields(err)
+	if len(fields) != 1 || fields[0].Key != "user_id" {
+		t.Errorf("Fields() = %v, want [user_id=42]", fields)
+	}
+
+	errx.Annotate(nil, "Nothing") // must not panic
+}
+
+func TestOps_Output(t *testing.T) {
+	t.Parallel()
+
+	err := errx.Wrap(getUser(true)).WithOp("HTTP GET /users/{id}")
+
+	errObj := logSlogAttr(t, errx.SlogAttr(err))
+	ops, ok := errObj["ops"].([]any)
+	if !ok || len(ops) != 3 || ops[0] != "HTTP GET /users/{id}" {
+		t.Errorf("ops = %v, want the op path", errObj["ops"])
+	}
+
+	got := fmt.Sprintf("%+v", err)
+	for _, want := range []string{
+		"ops: HTTP GET /users/{id} > UserService.Get > UserRepo.Find",
+		"op: UserRepo.Find",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%%+v output missing %q:\n%s", want, got)
+		}
+	}
+
+	if _, ok := logSlogAttr(t, errx.SlogAttr(errx.New("boom")))["ops"]; ok {
+		t.Error("ops should be omitted when no op is recorded")
+	}
+}
@@ -0,0 +1,56 @@
+package errx
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// maxPanicFrames bounds the frames between the recovering code and the panic site
+// (the deferred function, runtime.gopanic and the runtime's panic helpers).
+const maxPanicFrames = 16
+
+// FromPanic converts a value recovered from a panic into an Error with code Internal,
+// the panic value as the "panic" field and a stack captured at the panic site.
+// If the value is an error, it becomes the cause. The panic value stays in the message
+// for logs; the public message is the generic one of the [PublicMessagePolicy]
+// ("internal error" by default), so transports never send it to clients.
+// Call it from the deferred function:
+//
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        err = errx.FromPanic(r)
+//	    }
+//	}()
+func FromPanic(v any) *Error {
+	e := &Error{
+		code:      Internal,
+		fields:    []slog.Attr{slog.Any("panic", v)},
+		stack:     panicStack(),
+		publicMsg: genericMessage(),
+	}
+	if err, ok := v.(error); ok {
+		e.msg = "panic"
+		e.cause = err
+	} else {
+		e.msg = fmt.Sprintf("panic: %v", v)
+	}
+	return e
+}
+
+// panicStack captures the stack of the panicking goroutine starting at the panic site.
+// Frames of the recovering code, down to and including runtime.gopanic, are dropped;
+// if there is no panic in progress, the stack starts at the caller of FromPanic.
+func panicStack() *Stack {
+	depth := stackDepth()
+	pcs := make([]uintptr, depth+maxPanicFrames)
+	n := runtime.Callers(3, pcs) // skip runtime.Callers, panicStack and FromPanic
+	pcs = pcs[:n]
+	for i, pc := range pcs[:min(n, maxPanicFrames)] {
+		if f := runtime.FuncForPC(pc - 1); f != nil && f.Name() == "runtime.gopanic" {
+			pcs = pcs[i+1:]
+			break
+		}
+	}
+	return &Stack{pcs: pcs[:min(len(pcs), depth)]}
+}
@@ -0,0 +1,88 @@
+package errx_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func recoverPanic(f func()) (err *errx.Error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errx.FromPanic(r)
+		}
+	}()
+	f()
+	return nil
+}
+
+//go:noinline
+func panickingFunc() {
+	panic("boom")
+}
+
+func TestFromPanic(t *testing.T) {
+	t.Parallel()
+
+	err := recoverPanic(panickingFunc)
+	if err == nil {
+		t.Fatal("recoverPanic should return an error")
+	}
+	if err.Error() != "panic: boom" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "panic: boom")
+	}
+	if err.Code() != errx.Internal {
+		t.Errorf("Code() = %q, want %q", err.Code(), errx.Internal)
+	}
+	if got := errx.PublicMessage(err); got != "internal error" {
+		t.Errorf("PublicMessage() = %q, want %q", got, "internal error")
+	}
+	fields := errx.Fields(err)
+	if len(fields) != 1 || fields[0].Key != "panic" || fields[0].Value.Any() != "boom" {
+		t.Errorf("fields = %v, want panic=boom", fields)
+	}
+
+	frames := errx.StackOf(err).Frames()
+	if len(frames) == 0 {
+		t.Fatal("expected a stack")
+	}
+	if !strings.HasSuffix(frames[0].Function, "panickingFunc") {
+		t.Errorf("top frame = %q, want the panic site", frames[0].Function)
+	}
+}
+
+func TestFromPanic_Error(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("nil map")
+	err := recoverPanic(func() { panic(cause) })
+	if !errors.Is(err, cause) {
+		t.Error("a panic with an error value should wrap it")
+	}
+	if err.Error() != "panic: nil map" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "panic: nil map")
+	}
+}
+
+func TestFromPanic_RuntimeError(t *testing.T) {
+	t.Parallel()
+
+	err := recoverPanic(func() {
+		var m map[string]int
+		m["x"] = 1
+	})
+	if top := errx.StackOf(err).Frames()[0]; !strings.Contains(top.Function, "TestFromPanic_RuntimeError") {
+		t.Errorf("top frame = %q, want the faulting function", top.Function)
+	}
+}
+
+func TestFromPanic_NoPanic(t *testing.T) {
+	t.Parallel()
+
+	err := errx.FromPanic("value")
+	if top := errx.StackOf(err).Frames()[0]; !strings.Contains(top.Function, "TestFromPanic_NoPanic") {
+		t.Errorf("top frame = %q, want the caller", top.Function)
+	}
+}
@@ -0,0 +1,254 @@
+// Package protodetail converts errx detail objects to and from proto messages.
+// It is shared by gerr and cerr so that both transports emit the same
+// google.rpc error details for the same errx error.
+//
+// Importing the package registers converters to and from the google.rpc
+// error_details messages for the built-in errx detail types (see errx.RegisterDetail).
+package protodetail
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/mickamy/errx"
+)
+
+func init() {
+	register("BadRequest", badRequestToProto, badRequestFromProto)
+	register("PreconditionFailure", preconditionFailureToProto, preconditionFailureFromProto)
+	register("ResourceInfo", resourceInfoToProto, resourceInfoFromProto)
+	register("ErrorInfo", errorInfoToProto, errorInfoFromProto)
+	register("QuotaFailure", quotaFailureToProto, quotaFailureFromProto)
+	register("RetryInfo", retryInfoToProto, retryInfoFromProto)
+	register("DebugInfo", debugInfoToProto, debugInfoFromProto)
+	register("Help", helpToProto, helpFromProto)
+	register("RequestInfo", requestInfoToProto, requestInfoFromProto)
+	register("LocalizedMessage", localizedMessageToProto, localizedMessageFromProto)
+}
+
+// register re-registers a built-in errx detail type under its name,
+// adding converters to and from its google.rpc error_details counterpart.
+func register[T any, M proto.Message](name string, to func(*T) M, from func(M) *T) {
+	errx.RegisterDetail(errx.DetailType{
+		Name: name,
+		New:  func() any { return new(T) },
+		ToProto: func(d any) any {
+			v, ok := d.(*T)
+			if !ok {
+				return nil
+			}
+			return to(v)
+		},
+		FromProto: func(m any) (any, bool) {
+			pm, ok := m.(M)
+			if !ok {
+				return nil, false
+			}
+			return from(pm), true
+		},
+	})
+}
+
+// ToProto converts a detail object to a proto.Message using the ToProto converter
+// registered with errx.RegisterDetail. Built-in errx detail types are registered
+// by this package and map to their google.rpc error_details counterparts.
+// Proto messages are returned as-is. Returns nil for unrecognized details.
+func ToProto(d any) proto.Message {
+	if pm, ok := d.(proto.Message); ok {
+		return pm
+	}
+	if dt, ok := errx.LookupDetail(d); ok && dt.ToProto != nil {
+		if pm, ok := dt.ToProto(d).(proto.Message); ok {
+			return pm
+		}
+	}
+	return nil
+}
+
+// DetailsOf returns the details of err (see errx.DetailsOf), with a RetryInfo
+// detail appended for the retry-after hint of err (see errx.RetryAfterOf)
+// unless the details already carry one.
+func DetailsOf(err error) []any {
+	details := errx.DetailsOf(err)
+	d, ok := errx.RetryAfterOf(err)
+	if !ok {
+		return details
+	}
+	for _, detail := range details {
+		switch detail.(type) {
+		case *errx.RetryInfoDetail, *errdetails.RetryInfo:
+			return details
+		}
+	}
+	return append(details, errx.RetryInfo(d))
+}
+
+// FromProto converts a proto message back to a detail object.
+// Messages claimed by a FromProto converter registered with errx.RegisterDetail
+// (including the google.rpc error details of the built-in types) are converted;
+// all others are returned as-is.
+func FromProto(m proto.Message) any {
+	if d, ok := errx.DetailFromProto(m); ok {
+		return d
+	}
+	return m
+}
+
+func badRequestToProto(v *errx.BadRequestDetail) *errdetails.BadRequest {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(v.Violations))
+	for i, fv := range v.Violations {
+		violations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       fv.Field,
+			Description: fv.Description,
+		}
+	}
+	return &errdetails.BadRequest{FieldViolations: violations}
+}
+
+func badRequestFromProto(m *errdetails.BadRequest) *errx.BadRequestDetail {
+	violations := make([]errx.BadRequestFieldViolation, len(m.GetFieldViolations()))
+	for i, fv := range m.GetFieldViolations() {
+		violations[i] = errx.BadRequestFieldViolation{
+			Field:       fv.GetField(),
+			Description: fv.GetDescription(),
+		}
+	}
+	return errx.BadRequest(violations...)
+}
+
+func preconditionFailureToProto(v *errx.PreconditionFailureDetail) *errdetails.PreconditionFailure {
+	violations := make([]*errdetails.PreconditionFailure_Violation, len(v.Violations))
+	for i, pv := range v.Violations {
+		violations[i] = &errdetails.PreconditionFailure_Violation{
+			Type:        pv.Type,
+			Subject:     pv.Subject,
+			Description: pv.Description,
+		}
+	}
+	return &errdetails.PreconditionFailure{Violations: violations}
+}
+
+func preconditionFailureFromProto(m *errdetails.PreconditionFailure) *errx.PreconditionFailureDetail {
+	violations := make([]errx.PreconditionViolation, len(m.GetViolations()))
+	for i, pv := range m.GetViolations() {
+		violations[i] = errx.PreconditionViolation{
+			Type:        pv.GetType(),
+			Subject:     pv.GetSubject(),
+			Description: pv.GetDescription(),
+		}
+	}
+	return errx.PreconditionFailure(violations...)
+}
+
+func resourceInfoToProto(v *errx.ResourceInfoDetail) *errdetails.ResourceInfo {
+	return &errdetails.ResourceInfo{
+		ResourceType: v.ResourceType,
+		ResourceName: v.ResourceName,
+		Owner:        v.Owner,
+		Description:  v.Description,
+	}
+}
+
+func resourceInfoFromProto(m *errdetails.ResourceInfo) *errx.ResourceInfoDetail {
+	return errx.ResourceInfo(m.GetResourceType(), m.GetResourceName(), m.GetOwner(), m.GetDescription())
+}
+
+// errorInfoToProto redacts the values of sensitive metadata keys.
+func errorInfoToProto(v *errx.ErrorInfoDetail) *errdetails.ErrorInfo {
+	v = v.Redacted()
+	return &errdetails.ErrorInfo{
+		Reason:   v.Reason,
+		Domain:   v.Domain,
+		Metadata: v.Metadata,
+	}
+}
+
+func errorInfoFromProto(m *errdetails.ErrorInfo) *errx.ErrorInfoDetail {
+	return errx.ErrorInfo(m.GetReason(), m.GetDomain(), m.GetMetadata())
+}
+
+func quotaFailureToProto(v *errx.QuotaFailureDetail) *errdetails.QuotaFailure {
+	violations := make([]*errdetails.QuotaFailure_Violation, len(v.Violations))
+	for i, qv := range v.Violations {
+		violations[i] = &errdetails.QuotaFailure_Violation{
+			Subject:     qv.Subject,
+			Description: qv.Description,
+		}
+	}
+	return &errdetails.QuotaFailure{Violations: violations}
+}
+
+func quotaFailureFromProto(m *errdetails.QuotaFailure) *errx.QuotaFailureDetail {
+	violations := make([]errx.QuotaViolation, len(m.GetViolations()))
+	for i, qv := range m.GetViolations() {
+		violations[i] = errx.QuotaViolation{
+			Subject:     qv.GetSubject(),
+			Description: qv.GetDescription(),
+		}
+	}
+	return errx.QuotaFailure(violations...)
+}
+
+func retryInfoToProto(v *errx.RetryInfoDetail) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(v.RetryDelay)}
+}
+
+func retryInfoFromProto(m *errdetails.RetryInfo) *errx.RetryInfoDetail {
+	return errx.RetryInfo(m.GetRetryDelay().AsDuration())
+}
+
+func debugInfoToProto(v *errx.DebugInfoDetail) *errdetails.DebugInfo {
+	return &errdetails.DebugInfo{
+		StackEntries: v.StackEntries,
+		Detail:       v.Detail,
+	}
+}
+
+func debugInfoFromProto(m *errdetails.DebugInfo) *errx.DebugInfoDetail {
+	return errx.DebugInfo(m.GetStackEntries(), m.GetDetail())
+}
+
+func helpToProto(v *errx.HelpDetail) *errdetails.Help {
+	links := make([]*errdetails.Help_Link, len(v.Links))
+	for i, l := range v.Links {
+		links[i] = &errdetails.Help_Link{
+			Description: l.Description,
+			Url:         l.URL,
+		}
+	}
+	return &errdetails.Help{Links: links}
+}
+
+func helpFromProto(m *errdetails.Help) *errx.HelpDetail {
+	links := make([]errx.HelpLink, len(m.GetLinks()))
+	for i, l := range m.GetLinks() {
+		links[i] = errx.HelpLink{
+			Description: l.GetDescription(),
+			URL:         l.GetUrl(),
+		}
+	}
+	return errx.Help(links...)
+}
+
+func requestInfoToProto(v *errx.RequestInfoDetail) *errdetails.RequestInfo {
+	return &errdetails.RequestInfo{
+		RequestId:   v.RequestID,
+		ServingData: v.ServingData,
+	}
+}
+
+func requestInfoFromProto(m *errdetails.RequestInfo) *errx.RequestInfoDetail {
+	return errx.RequestInfo(m.GetRequestId(), m.GetServingData())
+}
+
+func localizedMessageToProto(v *errx.LocalizedMessageDetail) *errdetails.LocalizedMessage {
+	return &errdetails.LocalizedMessage{
+		Locale:  v.Locale,
+		Message: v.Message,
+	}
+}
+
+func localizedMessageFromProto(m *errdetails.LocalizedMessage) *errx.LocalizedMessageDetail {
+	return errx.LocalizedMessage(m.GetLocale(), m.GetMessage())
+}
@@ -0,0 +1,217 @@
+package protodetail_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/protodetail"
+)
+
+type planDetail struct {
+	Plan string `json:"plan"`
+}
+
+func init() {
+	errx.RegisterDetail(errx.DetailType{
+		Name: "Plan",
+		New:  func() any { return &planDetail{} },
+		ToProto: func(d any) any {
+			return wrapperspb.String(d.(*planDetail).Plan) //nolint:forcetypeassert // registered type
+		},
+		FromProto: func(m any) (any, bool) {
+			sv, ok := m.(*wrapperspb.StringValue)
+			if !ok {
+				return nil, false
+			}
+			return &planDetail{Plan: sv.GetValue()}, true
+		},
+	})
+}
+
+func TestToProto_RedactsErrorInfo(t *testing.T) { //nolint:paralleltest // registers a global sensitive key
+	d := errx.ErrorInfo("LOGIN_FAILED", "example.com", map[string]string{"x-proto-token": "tok-1", "user": "bob"})
+	errx.RegisterSensitiveKeys("x-proto-token")
+
+	ei, ok := protodetail.ToProto(d).(*errdetails.ErrorInfo)
+	if !ok {
+		t.Fatalf("ToProto() = %T, want *errdetails.ErrorInfo", protodetail.ToProto(d))
+	}
+	if m := ei.GetMetadata(); m["x-proto-token"] != "[REDACTED]" || m["user"] != "bob" {
+		t.Errorf("metadata = %v, want the token redacted", m)
+	}
+	if d.Metadata["x-proto-token"] != "tok-1" {
+		t.Errorf("detail metadata = %v, want the original value kept", d.Metadata)
+	}
+}
+
+func TestToProto(t *testing.T) {
+	t.Parallel()
+
+	t.Run("built-in detail", func(t *testing.T) {
+		t.Parallel()
+		pm := protodetail.ToProto(errx.ResourceInfo("User", "42", "", "not found"))
+		ri, ok := pm.(*errdetails.ResourceInfo)
+		if !ok {
+			t.Fatalf("type = %T, want *errdetails.ResourceInfo", pm)
+		}
+		if ri.GetResourceType() != "User" || ri.GetResourceName() != "42" {
+			t.Errorf("unexpected ResourceInfo: %v", ri)
+		}
+	})
+
+	t.Run("all built-in details", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			detail any
+			check  func(proto.Message) bool
+		}{
+			{errx.FieldViolation("email", "invalid"), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.BadRequest)
+				return ok && v.GetFieldViolations()[0].GetField() == "email"
+			}},
+			{errx.PreconditionFailure(errx.PreconditionViolation{Type: "TOS"}), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.PreconditionFailure)
+				return ok && v.GetViolations()[0].GetType() == "TOS"
+			}},
+			{errx.ErrorInfo("REASON", "example.com", nil), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.ErrorInfo)
+				return ok && v.GetReason() == "REASON"
+			}},
+			{errx.QuotaFailure(errx.QuotaViolation{Subject: "project:abc"}), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.QuotaFailure)
+				return ok && v.GetViolations()[0].GetSubject() == "project:abc"
+			}},
+			{errx.RetryInfo(1500 * time.Millisecond), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.RetryInfo)
+				return ok && v.GetRetryDelay().AsDuration() == 1500*time.Millisecond
+			}},
+			{errx.DebugInfo([]string{"main.go:42"}, "nil pointer"), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.DebugInfo)
+				return ok && v.GetDetail() == "nil pointer" && len(v.GetStackEntries()) == 1
+			}},
+			{errx.Help(errx.HelpLink{Description: "docs", URL: "https://example.com"}), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.Help)
+				return ok && v.GetLinks()[0].GetUrl() == "https://example.com"
+			}},
+			{errx.RequestInfo("req-1", "shard-7"), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.RequestInfo)
+				return ok && v.GetRequestId() == "req-1" && v.GetServingData() == "shard-7"
+			}},
+			{errx.LocalizedMessage("en", "Hello"), func(m proto.Message) bool {
+				v, ok := m.(*errdetails.LocalizedMessage)
+				return ok && v.GetLocale() == "en" && v.GetMessage() == "Hello"
+			}},
+		}
+		for _, tt := range tests {
+			if pm := protodetail.ToProto(tt.detail); !tt.check(pm) {
+				t.Errorf("ToProto(%T) = %v", tt.detail, pm)
+			}
+		}
+	})
+
+	t.Run("registered detail", func(t *testing.T) {
+		t.Parallel()
+		pm := protodetail.ToProto(&planDetail{Plan: "pro"})
+		sv, ok := pm.(*wrapperspb.StringValue)
+		if !ok || sv.GetValue() != "pro" {
+			t.Errorf("ToProto = %v, want StringValue(pro)", pm)
+		}
+	})
+
+	t.Run("proto message passes through", func(t *testing.T) {
+		t.Parallel()
+		in := &errdetails.RetryInfo{}
+		if pm := protodetail.ToProto(in); pm != in {
+			t.Errorf("ToProto = %v, want the same message", pm)
+		}
+	})
+
+	t.Run("unknown detail", func(t *testing.T) {
+		t.Parallel()
+		if pm := protodetail.ToProto("opaque"); pm != nil {
+			t.Errorf("ToProto = %v, want nil", pm)
+		}
+	})
+}
+
+func TestFromProto(t *testing.T) {
+	t.Parallel()
+
+	if d, ok := protodetail.FromProto(wrapperspb.String("pro")).(*planDetail); !ok || d.Plan != "pro" {
+		t.Errorf("FromProto should restore the registered detail, got %#v", d)
+	}
+
+	br := &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "invalid"}},
+	}
+	if d, ok := protodetail.FromProto(br).(*errx.BadRequestDetail); !ok || d.Violations[0].Field != "email" {
+		t.Errorf("FromProto should convert google.rpc details to errx details, got %#v", d)
+	}
+
+	unknown := wrapperspb.Int64(42)
+	if d := protodetail.FromProto(unknown); d != unknown {
+		t.Errorf("FromProto = %v, want the message unchanged", d)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		detail any
+	}{
+		{"BadRequest", errx.FieldViolation("email", "invalid")},
+		{"PreconditionFailure", errx.PreconditionFailure(errx.PreconditionViolation{
+			Type: "TOS", Subject: "user:42", Description: "terms not accepted",
+		})},
+		{"ResourceInfo", errx.ResourceInfo("User", "42", "team-a", "not found")},
+		{"ErrorInfo", errx.ErrorInfo("REASON", "example.com", map[string]string{"id": "42"})},
+		{"QuotaFailure", errx.QuotaFailure(errx.QuotaViolation{Subject: "project:abc", Description: "limit"})},
+		{"RetryInfo", errx.RetryInfo(1500 * time.Millisecond)},
+		{"DebugInfo", errx.DebugInfo([]string{"main.go:42"}, "nil pointer")},
+		{"Help", errx.Help(errx.HelpLink{Description: "docs", URL: "https://example.com"})},
+		{"RequestInfo", errx.RequestInfo("req-1", "shard-7")},
+		{"LocalizedMessage", errx.LocalizedMessage("en", "Hello")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pm := protodetail.ToProto(tt.detail)
+			if pm == nil {
+				t.Fatalf("ToProto(%T) = nil", tt.detail)
+			}
+			if got := protodetail.FromProto(pm); !reflect.DeepEqual(got, tt.detail) {
+				t.Errorf("FromProto(ToProto()) = %#v, want %#v", got, tt.detail)
+			}
+		})
+	}
+}
+
+func TestDetailsOf(t *testing.T) {
+	t.Parallel()
+
+	err := errx.New("busy").WithCode(errx.Unavailable).WithRetryAfter(2 * time.Second)
+	details := protodetail.DetailsOf(err)
+	if len(details) != 1 {
+		t.Fatalf("details length = %d, want 1", len(details))
+	}
+	if ri, ok := details[0].(*errx.RetryInfoDetail); !ok || ri.RetryDelay != 2*time.Second {
+		t.Errorf("details[0] = %#v, want RetryInfo of 2s", details[0])
+	}
+
+	withInfo := err.WithDetails(errx.RetryInfo(time.Second))
+	if n := len(protodetail.DetailsOf(withInfo)); n != 1 {
+		t.Errorf("details length = %d, want 1 (existing RetryInfo is kept)", n)
+	}
+	if n := len(protodetail.DetailsOf(errx.New("fail"))); n != 0 {
+		t.Errorf("details length = %d, want 0", n)
+	}
+}
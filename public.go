@@ -0,0 +1,123 @@
+package errx
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// defaultGenericMessage is the public message of [PublicMessagePolicy] when none is set,
+// and of errors without an errx message when no policy is installed.
+const defaultGenericMessage = "internal error"
+
+// WithPublicMessage returns a copy of the error with a message that is safe to show
+// to clients. Transports send [PublicMessage] rather than Error(), which includes
+// every wrapped cause (e.g. raw driver messages); logs keep the full chain.
+func (e *Error) WithPublicMessage(msg string) *Error {
+	cp := *e
+	cp.publicMsg = msg
+	return &cp
+}
+
+// PublicMessagePolicy decides the public message of errors that have none set
+// with [Error.WithPublicMessage].
+type PublicMessagePolicy struct {
+	// GenericCodes are codes whose errors (including descendants) get GenericMessage,
+	// e.g. Internal and Unknown.
+	GenericCodes []Code
+
+	// GenericUncoded gives GenericMessage to errors without a code.
+	GenericUncoded bool
+
+	// GenericMessage is the message sent instead of the error's own message.
+	// Defaults to "internal error".
+	GenericMessage string
+
+	// FullErrorText falls back to Error(), the whole cause chain, instead of the
+	// error's own message. Use it only when every client may see internal causes.
+	FullErrorText bool
+}
+
+var publicMessagePolicy atomic.Pointer[PublicMessagePolicy]
+
+// SetPublicMessagePolicy installs the policy used by [PublicMessage].
+// Must be called at program initialization (e.g. in init()), before serving requests.
+func SetPublicMessagePolicy(p PublicMessagePolicy) {
+	p.GenericCodes = slices.Clone(p.GenericCodes)
+	if p.GenericMessage == "" {
+		p.GenericMessage = defaultGenericMessage
+	}
+	publicMessagePolicy.Store(&p)
+}
+
+// PublicMessage returns the client-safe message of err: the first public message
+// set with [Error.WithPublicMessage] in the chain (outermost first), otherwise the
+// [CodeInfo.PublicMessage] of the error's code, otherwise the generic message if the
+// [PublicMessagePolicy] covers the error's code, otherwise the own message of the
+// outermost errx layer that has one (an [*Error] or [*SentinelError]), without the
+// messages of its causes. Errors without such a layer (e.g. a bare driver error) get
+// the [CodeInfo.Description] of a client code, and the generic message if they have no
+// code or a server fault code (see [IsServerFault]).
+// err.Error() is used only with [PublicMessagePolicy.FullErrorText].
+// Returns "" if err is nil.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	var msg string
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok && ex.publicMsg != "" { //nolint:errorlint // walk visits every layer
+			msg = ex.publicMsg
+			return false
+		}
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	c := CodeOf(err)
+	if msg := inheritedInfo(c, func(info CodeInfo) string { return info.PublicMessage }); msg != "" {
+		return msg
+	}
+	if p := publicMessagePolicy.Load(); p != nil {
+		if c == "" && p.GenericUncoded || c.in(p.GenericCodes) {
+			return p.GenericMessage
+		}
+		if p.FullErrorText {
+			return err.Error()
+		}
+	}
+	if msg := ownMessage(err); msg != "" {
+		return msg
+	}
+	if c != "" && !IsServerFault(c) {
+		if desc := inheritedInfo(c, func(info CodeInfo) string { return info.Description }); desc != "" {
+			return desc
+		}
+	}
+	return genericMessage()
+}
+
+// genericMessage returns the GenericMessage of the installed [PublicMessagePolicy],
+// or "internal error" if there is none.
+func genericMessage() string {
+	if p := publicMessagePolicy.Load(); p != nil {
+		return p.GenericMessage
+	}
+	return defaultGenericMessage
+}
+
+// ownMessage returns the message of the outermost errx layer that has one,
+// without the messages of its causes. Foreign layers are skipped.
+func ownMessage(err error) string {
+	var msg string
+	walk(err, func(err error) bool {
+		switch x := err.(type) { //nolint:errorlint // walk visits every layer
+		case *Error:
+			msg = x.msg
+		case *SentinelError:
+			msg = x.msg
+		}
+		return msg == ""
+	})
+	return msg
+}
@@ -0,0 +1,121 @@
+package errx_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func TestPublicMessage(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("pq: relation \"users\" does not exist")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"no errx message falls back to the generic message", errx.Wrap(dbErr).WithCode(errx.Internal), "internal error"},
+		{"bare foreign error", dbErr, "internal error"},
+		{"own message without causes", errx.Wrapf(dbErr, "query users").WithCode(errx.Internal), "query users"},
+		{
+			"fields only over a foreign cause",
+			fmt.Errorf("repo: %w", errx.Wrap(dbErr, "user_id", 42).WithCode(errx.Internal)),
+			"internal error",
+		},
+		{
+			"client code without errx message",
+			fmt.Errorf("repo: %w", errx.Wrap(dbErr, "user_id", 42).WithCode(errx.NotFound)),
+			"A requested entity was not found.",
+		},
+		{"bare client error", fmt.Errorf("open: %w", fs.ErrNotExist), "A requested entity was not found."},
+		{"inner errx message", fmt.Errorf("repo: %w", errx.New("user not found")), "user not found"},
+		{
+			"sentinel message",
+			errx.Wrap(fmt.Errorf("lookup: %w", errx.NewSentinel("user not found", errx.NotFound))),
+			"user not found",
+		},
+		{"public message", errx.Wrap(dbErr).WithCode(errx.NotFound).WithPublicMessage("user not found"), "user not found"},
+		{
+			"inner public message",
+			fmt.Errorf("repo: %w", errx.Wrap(dbErr).WithPublicMessage("try again later")),
+			"try again later",
+		},
+		{
+			"outermost wins",
+			errx.Wrap(errx.New("inner").WithPublicMessage("inner public")).WithPublicMessage("outer public"),
+			"outer public",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errx.PublicMessage(tt.err); got != tt.want {
+				t.Errorf("PublicMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicMessage_KeptInChain(t *testing.T) {
+	t.Parallel()
+
+	err := errx.Wrapf(errors.New("dial tcp: refused"), "query users").WithPublicMessage("service unavailable")
+	if err.Error() != "query users: dial tcp: refused" {
+		t.Errorf("Error() = %q, want the full chain", err.Error())
+	}
+}
+
+func TestSetPublicMessagePolicy(t *testing.T) { //nolint:paralleltest // installs a global policy
+	errx.SetPublicMessagePolicy(errx.PublicMessagePolicy{
+		GenericCodes:   []errx.Code{errx.Internal},
+		GenericUncoded: true,
+	})
+	t.Cleanup(func() { errx.SetPublicMessagePolicy(errx.PublicMessagePolicy{}) })
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"internal", errx.New("pq: syntax error").WithCode(errx.Internal), "internal error"},
+		{"uncoded", errors.New("boom"), "internal error"},
+		{"other code", errx.New("user not found").WithCode(errx.NotFound), "user not found"},
+		{
+			"explicit public message",
+			errx.New("pq: timeout").WithCode(errx.Internal).WithPublicMessage("try again"),
+			"try again",
+		},
+	}
+	for _, tt := range tests {
+		if got := errx.PublicMessage(tt.err); got != tt.want {
+			t.Errorf("%s: PublicMessage() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	errx.SetPublicMessagePolicy(errx.PublicMessagePolicy{GenericCodes: []errx.Code{errx.Internal}, GenericMessage: "oops"})
+	if got := errx.PublicMessage(errx.New("boom")); got != "boom" {
+		t.Errorf("uncoded without GenericUncoded = %q, want %q", got, "boom")
+	}
+	if got := errx.PublicMessage(errors.New("boom")); got != "oops" {
+		t.Errorf("no errx message = %q, want %q", got, "oops")
+	}
+	if got := errx.PublicMessage(errx.New("x").WithCode(errx.Internal)); got != "oops" {
+		t.Errorf("custom GenericMessage = %q, want %q", got, "oops")
+	}
+}
+
+func TestSetPublicMessagePolicy_FullErrorText(t *testing.T) { //nolint:paralleltest // installs a global policy
+	errx.SetPublicMessagePolicy(errx.PublicMessagePolicy{FullErrorText: true})
+	t.Cleanup(func() { errx.SetPublicMessagePolicy(errx.PublicMessagePolicy{}) })
+
+	err := errx.Wrapf(errors.New("dial tcp: refused"), "query users")
+	if got := errx.PublicMessage(err); got != err.Error() {
+		t.Errorf("PublicMessage() = %q, want %q", got, err.Error())
+	}
+}
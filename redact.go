@@ -0,0 +1,176 @@
+package errx
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// redacted replaces sensitive values in every output of errx.
+const redacted = "[REDACTED]"
+
+// compile-time checks
+var (
+	_ slog.LogValuer = SecretValue{}
+	_ fmt.Formatter  = SecretValue{}
+)
+
+// SecretValue holds a value that must not appear in error messages, logs
+// or transport output. Create one with [Secret].
+type SecretValue struct {
+	v any
+}
+
+// Secret marks v as sensitive. Use it as a field value or as an argument of [Wrapf]:
+//
+//	errx.New("login failed", "password", errx.Secret(pw))
+//	errx.Wrapf(err, "send mail to %s", errx.Secret(email))
+//
+// The value is printed, logged and serialized as "[REDACTED]". Trusted sinks
+// can get the original value with [Unredacted] or [SecretValue.Reveal].
+func Secret(v any) SecretValue {
+	return SecretValue{v: v}
+}
+
+// Reveal returns the original value.
+func (s SecretValue) Reveal() any { return s.v }
+
+// String implements fmt.Stringer.
+func (s SecretValue) String() string { return redacted }
+
+// Format implements fmt.Formatter; every verb prints "[REDACTED]".
+func (s SecretValue) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+// LogValue implements slog.LogValuer.
+func (s SecretValue) LogValue() slog.Value { return slog.StringValue(redacted) }
+
+// MarshalJSON implements json.Marshaler.
+func (s SecretValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+var sensitiveKeys atomic.Pointer[map[string]struct{}]
+
+// RegisterSensitiveKeys marks field keys (case-insensitive) whose values are always
+// redacted, whether or not they are wrapped in [Secret]. It applies to fields and
+// to the metadata of [ErrorInfo] details when they are output, so keys registered
+// after a detail was created are redacted too; [DetailsOf] keeps the original values.
+// Must be called at program initialization (e.g. in init()), before creating errors.
+func RegisterSensitiveKeys(keys ...string) {
+	m := map[string]struct{}{}
+	if old := sensitiveKeys.Load(); old != nil {
+		for k := range *old {
+			m[k] = struct{}{}
+		}
+	}
+	for _, k := range keys {
+		m[strings.ToLower(k)] = struct{}{}
+	}
+	sensitiveKeys.Store(&m)
+}
+
+// IsSensitiveKey reports whether key was registered with [RegisterSensitiveKeys].
+func IsSensitiveKey(key string) bool {
+	m := sensitiveKeys.Load()
+	if m == nil {
+		return false
+	}
+	_, ok := (*m)[strings.ToLower(key)]
+	return ok
+}
+
+// Unredacted makes [SlogAttr] (or [Error.LogValue] via [SetDefaultSlogOptions])
+// emit the original values of secrets and sensitive keys, and messages built
+// from secrets. Use it only for trusted sinks.
+func Unredacted() SlogOption {
+	return func(c *slogConfig) {
+		c.unredacted = true
+	}
+}
+
+// redactAttr masks a if its key is sensitive, and masks sensitive keys inside groups.
+// Secret values are left as they are; they redact themselves when resolved.
+func redactAttr(a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		masked := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			masked[i] = redactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+	}
+	return a
+}
+
+// redactMetadata returns a copy of metadata with the values of sensitive keys redacted.
+func redactMetadata(metadata map[string]string) map[string]string {
+	cp := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if IsSensitiveKey(k) {
+			v = redacted
+		}
+		cp[k] = v
+	}
+	return cp
+}
+
+// revealAttr replaces secret values in a (and inside groups) by their original value.
+func revealAttr(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindLogValuer:
+		if s, ok := a.Value.Any().(SecretValue); ok {
+			return slog.Any(a.Key, s.v)
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		revealed := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			revealed[i] = revealAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(revealed...)}
+	default:
+	}
+	return a
+}
+
+// revealArgs returns args with secret values replaced by their original value,
+// and whether any secret was found.
+func revealArgs(args []any) ([]any, bool) {
+	var revealed []any
+	for i, a := range args {
+		s, ok := a.(SecretValue)
+		if !ok {
+			continue
+		}
+		if revealed == nil {
+			revealed = append([]any(nil), args...)
+		}
+		revealed[i] = s.v
+	}
+	return revealed, revealed != nil
+}
+
+// unredactedError returns the message of err with the secrets of errx layers revealed.
+func unredactedError(err error) string {
+	ex, ok := err.(*Error) //nolint:errorlint // inspecting a single layer
+	if !ok {
+		return err.Error()
+	}
+	msg := ex.msg
+	if ex.secretMsg != "" {
+		msg = ex.secretMsg
+	}
+	if msg == "" && ex.cause != nil {
+		return unredactedError(ex.cause)
+	}
+	if ex.cause == nil {
+		return msg
+	}
+	return msg + ": " + unredactedError(ex.cause)
+}
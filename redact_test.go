@@ -0,0 +1,134 @@
+package errx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func TestSecret(t *testing.T) {
+	t.Parallel()
+
+	s := errx.Secret("hunter2")
+	for _, got := range []string{fmt.Sprint(s), fmt.Sprintf("%v|%+v|%s|%q|%d", s, s, s, s, s)} {
+		if strings.Contains(got, "hunter2") {
+			t.Errorf("formatted secret leaked: %q", got)
+		}
+	}
+	if b, _ := json.Marshal(s); string(b) != `"[REDACTED]"` {
+		t.Errorf("json = %s, want %q", b, `"[REDACTED]"`)
+	}
+	if s.Reveal() != "hunter2" {
+		t.Errorf("Reveal() = %v, want %q", s.Reveal(), "hunter2")
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	t.Parallel()
+
+	inner := errx.New("smtp failed", "token", errx.Secret("tok-123"))
+	err := errx.Wrapf(inner, "send mail to %s", errx.Secret("a@example.com"))
+
+	b, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	var buf strings.Builder
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("msg", "error", err, errx.SlogAttr(err))
+
+	for name, out := range map[string]string{
+		"Error()":     err.Error(),
+		"%+v":         fmt.Sprintf("%+v", err),
+		"MarshalJSON": string(b),
+		"slog":        buf.String(),
+	} {
+		if strings.Contains(out, "a@example.com") || strings.Contains(out, "tok-123") {
+			t.Errorf("%s leaked a secret: %s", name, out)
+		}
+		if !strings.Contains(out, "[REDACTED]") {
+			t.Errorf("%s should contain [REDACTED]: %s", name, out)
+		}
+	}
+}
+
+func TestSlogAttr_Unredacted(t *testing.T) {
+	t.Parallel()
+
+	inner := errx.New("smtp failed", "token", errx.Secret("tok-123"))
+	err := errx.Wrapf(inner, "send mail to %s", errx.Secret("a@example.com"))
+
+	errObj := logSlogAttr(t, errx.SlogAttr(err, errx.Unredacted()))
+	if errObj["msg"] != "send mail to a@example.com: smtp failed" {
+		t.Errorf("msg = %v, want the revealed message", errObj["msg"])
+	}
+	if errObj["token"] != "tok-123" {
+		t.Errorf("token = %v, want %q", errObj["token"], "tok-123")
+	}
+}
+
+func TestRegisterSensitiveKeys_AfterErrorInfo(t *testing.T) { //nolint:paralleltest // registers global sensitive keys
+	err := errx.New("login failed").WithDetails(errx.ErrorInfo("LOGIN_FAILED", "example.com", map[string]string{
+		"x-test-late-token": "tok-1",
+	}))
+	errx.RegisterSensitiveKeys("X-Test-Late-Token")
+
+	encoded, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	for name, out := range map[string]string{"%+v": fmt.Sprintf("%+v", err), "JSON": string(encoded)} {
+		if strings.Contains(out, "tok-1") {
+			t.Errorf("%s leaked a key registered after the detail was created: %s", name, out)
+		}
+	}
+}
+
+func TestRegisterSensitiveKeys(t *testing.T) { //nolint:paralleltest // registers global sensitive keys
+	errx.RegisterSensitiveKeys("X-Test-Password")
+	if !errx.IsSensitiveKey("x-test-password") {
+		t.Fatal("IsSensitiveKey should match case-insensitively")
+	}
+
+	err := errx.New("login failed",
+		"x-test-password", "pw-1",
+		slog.Group("req", slog.String("x-test-password", "pw-2")),
+	).WithDetails(errx.ErrorInfo("LOGIN_FAILED", "example.com", map[string]string{
+		"x-test-password": "pw-3",
+		"user":            "bob",
+	}))
+
+	var buf strings.Builder
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("msg", "error", err)
+	verbose := fmt.Sprintf("%+v", err)
+	encoded, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	for name, out := range map[string]string{"slog": buf.String(), "%+v": verbose, "JSON": string(encoded)} {
+		if strings.Contains(out, "pw-") {
+			t.Errorf("%s leaked a sensitive field: %s", name, out)
+		}
+	}
+	info, ok := errx.DetailsOf(err)[0].(*errx.ErrorInfoDetail)
+	if !ok {
+		t.Fatalf("details[0] = %T, want *errx.ErrorInfoDetail", errx.DetailsOf(err)[0])
+	}
+	if info.Metadata["x-test-password"] != "pw-3" {
+		t.Errorf("metadata = %v, want the original value kept", info.Metadata)
+	}
+	if m := info.Redacted().Metadata; m["x-test-password"] != "[REDACTED]" || m["user"] != "bob" {
+		t.Errorf("Redacted() metadata = %v, want the password redacted", m)
+	}
+
+	tmpl := errx.Define(errx.Unauthenticated, "bad password {x-test-password} for {user}")
+	if got := tmpl.New("x-test-password", "pw-4", "user", "bob").Error(); got != "bad password [REDACTED] for bob" {
+		t.Errorf("template Error() = %q", got)
+	}
+	if got := logSlogAttr(t, errx.SlogAttr(err, errx.Unredacted()))["x-test-password"]; got != "pw-1" {
+		t.Errorf("unredacted field = %v, want %q", got, "pw-1")
+	}
+}
@@ -0,0 +1,97 @@
+package errx
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+)
+
+// CodeResolver returns the code of an error that does not implement [Coder],
+// or "" if it does not recognize it. It is called for each layer of the chain
+// on its own, so it should inspect err itself rather than unwrap it.
+type CodeResolver func(err error) Code
+
+// codeResolvers holds the registered resolvers, most recently registered first.
+var codeResolvers atomic.Pointer[[]CodeResolver]
+
+func init() {
+	RegisterCodeFor(context.Canceled, Canceled)
+	RegisterCodeFor(context.DeadlineExceeded, DeadlineExceeded)
+	RegisterCodeFor(fs.ErrNotExist, NotFound)
+	RegisterCodeFor(fs.ErrExist, AlreadyExists)
+	RegisterCodeFor(fs.ErrPermission, PermissionDenied)
+	RegisterCodeFor(http.ErrHandlerTimeout, DeadlineExceeded)
+	RegisterCodeForType[*http.MaxBytesError](OutOfRange)
+	RegisterCodeForType[*net.OpError](Unavailable)
+}
+
+// RegisterCodeResolver registers a resolver consulted by [CodeOf] (and thus [IsCode]
+// and every transport) for errors that do not implement [Coder].
+// Resolvers registered later take precedence over earlier ones, including the
+// built-in ones for context, io/fs, net and net/http errors.
+// Must be called at program initialization (e.g. in init()), before serving requests.
+func RegisterCodeResolver(r CodeResolver) {
+	if r == nil {
+		return
+	}
+	var rs []CodeResolver
+	if old := codeResolvers.Load(); old != nil {
+		rs = *old
+	}
+	rs = append([]CodeResolver{r}, rs...)
+	codeResolvers.Store(&rs)
+}
+
+// RegisterCodeFor maps errors matching target to code. An error matches if it is
+// target or reports so through an Is(error) bool method, like [errors.Is]:
+//
+//	errx.RegisterCodeFor(sql.ErrNoRows, errx.NotFound)
+//
+// It panics if target is nil or not comparable.
+// Must be called at program initialization (e.g. in init()), before serving requests.
+func RegisterCodeFor(target error, code Code) {
+	if target == nil || !reflect.TypeOf(target).Comparable() {
+		panic("errx: RegisterCodeFor requires a comparable, non-nil target")
+	}
+	RegisterCodeResolver(func(err error) Code {
+		if err == target { //nolint:errorlint // resolvers inspect a single layer
+			return code
+		}
+		//nolint:errorlint // resolvers inspect a single layer
+		if x, ok := err.(interface{ Is(error) bool }); ok && x.Is(target) {
+			return code
+		}
+		return ""
+	})
+}
+
+// RegisterCodeForType maps errors of type T to code, like [errors.As]:
+//
+//	errx.RegisterCodeForType[*url.Error](errx.Unavailable)
+//
+// Must be called at program initialization (e.g. in init()), before serving requests.
+func RegisterCodeForType[T error](code Code) {
+	RegisterCodeResolver(func(err error) Code {
+		if _, ok := err.(T); ok { //nolint:errorlint // resolvers inspect a single layer
+			return code
+		}
+		return ""
+	})
+}
+
+// resolveCode returns the code the registered resolvers give err, or "".
+func resolveCode(err error) Code {
+	rs := codeResolvers.Load()
+	if rs == nil {
+		return ""
+	}
+	for _, r := range *rs {
+		if c := r(err); c != "" {
+			return c
+		}
+	}
+	return ""
+}
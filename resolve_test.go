@@ -0,0 +1,70 @@
+package errx_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+var errNoRows = errors.New("no rows in result set")
+
+type driverError struct{ number int }
+
+func (e *driverError) Error() string { return fmt.Sprintf("driver error %d", e.number) }
+
+func TestRegisterCodeResolvers(t *testing.T) { //nolint:paralleltest // registers global resolvers
+	errx.RegisterCodeFor(errNoRows, errx.NotFound)
+	errx.RegisterCodeForType[*driverError](errx.Unavailable)
+	errx.RegisterCodeResolver(func(err error) errx.Code {
+		var de *driverError
+		if errors.As(err, &de) && de.number == 1062 {
+			return errx.AlreadyExists
+		}
+		return ""
+	})
+
+	_, openErr := os.Open(filepath.Join(t.TempDir(), "missing"))
+
+	tests := []struct {
+		name string
+		err  error
+		want errx.Code
+	}{
+		{"target", errNoRows, errx.NotFound},
+		{"wrapped target", fmt.Errorf("find user: %w", errNoRows), errx.NotFound},
+		{"type", &driverError{number: 2006}, errx.Unavailable},
+		{"later resolver takes precedence", &driverError{number: 1062}, errx.AlreadyExists},
+		{"errx layer", errx.Wrap(&driverError{number: 2006}, "op", "insert"), errx.Unavailable},
+		{"outer code wins", errx.Wrap(errNoRows).WithCode(errx.Internal), errx.Internal},
+		{"builtin fs", openErr, errx.NotFound},
+		{"builtin handler timeout", fmt.Errorf("serve: %w", http.ErrHandlerTimeout), errx.DeadlineExceeded},
+		{"builtin max bytes", &http.MaxBytesError{Limit: 4}, errx.OutOfRange},
+		{"builtin net", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, errx.Unavailable},
+		{"unrecognized", errors.New("plain"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) { //nolint:paralleltest // parent registers global resolvers
+			if got := errx.CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterCodeFor_PanicsOnNil(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterCodeFor(nil) did not panic")
+		}
+	}()
+	errx.RegisterCodeFor(nil, errx.NotFound)
+}
@@ -0,0 +1,105 @@
+package errx
+
+import "time"
+
+// Retryability classifies whether errors are worth retrying.
+// The zero value means the retryability is not set.
+type Retryability int8
+
+// Retryabilities.
+const (
+	Retryable Retryability = iota + 1
+	NotRetryable
+)
+
+// WithRetryable returns a copy of the error explicitly classified as retryable or not,
+// overriding the default derived from its code.
+func (e *Error) WithRetryable(retryable bool) *Error {
+	cp := *e
+	cp.retry = NotRetryable
+	if retryable {
+		cp.retry = Retryable
+	}
+	return &cp
+}
+
+// WithRetryAfter returns a copy of the error marked as retryable after d.
+// gerr and cerr send the hint as a RetryInfo detail, herr as a Retry-After header.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	cp := *e
+	cp.retry = Retryable
+	cp.retryAfter = d
+	return &cp
+}
+
+// IsRetryable reports whether the operation that failed with err is worth retrying.
+// The outermost explicit classification in the chain wins: [Error.WithRetryable] or
+// [Error.WithRetryAfter]. Otherwise the code decides: codes declared [Retryable] in
+// their [CodeInfo] and their descendants are retryable unless a nearer declaration
+// makes them [NotRetryable]; by default Unavailable, Aborted and ResourceExhausted are
+// retryable. The deprecated Temporary() method of foreign errors (e.g. net.Error) is
+// consulted only for errors without a code.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var explicit, temporary Retryability
+	walk(err, func(err error) bool {
+		switch x := err.(type) { //nolint:errorlint // walk visits every layer
+		case *Error:
+			explicit = x.retry
+			return explicit == 0
+		case interface{ Temporary() bool }:
+			if temporary == 0 {
+				temporary = NotRetryable
+				if x.Temporary() {
+					temporary = Retryable
+				}
+			}
+		}
+		return true
+	})
+	if explicit != 0 {
+		return explicit == Retryable
+	}
+	if c := CodeOf(err); c != "" {
+		return isRetryableCode(c)
+	}
+	return temporary == Retryable
+}
+
+// RetryAfterOf returns the retry-after hint of err: the outermost duration set with
+// [Error.WithRetryAfter] or carried by a [RetryInfoDetail] in the chain.
+func RetryAfterOf(err error) (time.Duration, bool) {
+	var d time.Duration
+	walk(err, func(err error) bool {
+		ex, ok := err.(*Error) //nolint:errorlint // walk visits every layer
+		if !ok {
+			return true
+		}
+		if ex.retryAfter > 0 {
+			d = ex.retryAfter
+			return false
+		}
+		for _, detail := range ex.details {
+			if ri, ok := detail.(*RetryInfoDetail); ok && ri.RetryDelay > 0 {
+				d = ri.RetryDelay
+				return false
+			}
+		}
+		return true
+	})
+	return d, d > 0
+}
+
+// Temporary reports whether the error is retryable (see [IsRetryable]).
+// It lets libraries that check the net.Error-style Temporary() method classify errx errors.
+func (e *Error) Temporary() bool { return IsRetryable(e) }
+
+// Timeout reports whether the error's code is DeadlineExceeded or one of its descendants.
+// It lets libraries that check the net.Error-style Timeout() method classify errx errors.
+func (e *Error) Timeout() bool { return CodeOf(e).Is(DeadlineExceeded) }
+
+func isRetryableCode(c Code) bool {
+	return inheritedInfo(c, func(info CodeInfo) Retryability { return info.Retryable }) == Retryable
+}
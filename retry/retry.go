@@ -0,0 +1,143 @@
+// Package retry runs operations until they succeed, fail with a non-retryable
+// error or run out of attempts, backing off exponentially with jitter in between.
+// Whether an error is retryable is decided by [errx.IsRetryable], and server-provided
+// delays ([errx.RetryAfterOf], e.g. a RetryInfo detail restored by gerr.FromStatus
+// or cerr.FromConnectError) take precedence over the computed backoff.
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/mickamy/errx"
+)
+
+// Clock abstracts waiting so that tests can run without real sleeps.
+type Clock interface {
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Option configures [Do].
+type Option func(*config)
+
+type config struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	jitter       float64
+	retryIf      func(error) bool
+	clock        Clock
+}
+
+// WithMaxAttempts sets the maximum number of attempts, including the first one.
+// Zero or less retries until the context is done. Defaults to 5.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the cap of the delay,
+// which doubles after every attempt. Defaults to 100ms and 10s.
+func WithBackoff(initial, maxDelay time.Duration) Option {
+	return func(c *config) {
+		c.initialDelay = initial
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithJitter sets the fraction (0 to 1) of each delay that is randomized:
+// a delay d becomes a random duration between d*(1-fraction) and d. Defaults to 0.5.
+func WithJitter(fraction float64) Option {
+	return func(c *config) {
+		c.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithRetryIf replaces [errx.IsRetryable] as the test for retryable errors.
+func WithRetryIf(f func(error) bool) Option {
+	return func(c *config) {
+		if f != nil {
+			c.retryIf = f
+		}
+	}
+}
+
+// WithClock sets the clock used to wait between attempts.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+// Do calls fn until it returns nil, returns a non-retryable error, the attempts
+// are exhausted or ctx is done. It returns nil on success; otherwise an *errx.Error
+// that wraps the last error and records the number of attempts in the "attempts" field.
+// If ctx ended the loop, the last error is joined with the context error and the
+// result is coded Canceled or DeadlineExceeded after the context (see errx.FromContext),
+// whatever the code of the last error.
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	cfg := &config{
+		maxAttempts:  5,
+		initialDelay: 100 * time.Millisecond,
+		maxDelay:     10 * time.Second,
+		jitter:       0.5,
+		retryIf:      errx.IsRetryable,
+		clock:        realClock{},
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	var last error
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return contextError(ctx, last, attempt-1)
+		}
+		last = fn(ctx)
+		if last == nil {
+			return nil
+		}
+		if !cfg.retryIf(last) || cfg.maxAttempts > 0 && attempt >= cfg.maxAttempts {
+			return errx.Wrap(last, "attempts", attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return contextError(ctx, last, attempt)
+		case <-cfg.clock.After(cfg.delay(attempt, last)):
+		}
+	}
+}
+
+// contextError returns the error of a loop that the done ctx ended: the context error
+// joined with the last error, coded after the context. The code is set explicitly
+// because a joined server fault (e.g. Unavailable) would otherwise take precedence.
+func contextError(ctx context.Context, last error, attempts int) error {
+	ctxErr := errx.FromContext(ctx)
+	return errx.Join(ctxErr, last).With("attempts", attempts).WithCode(ctxErr.Code())
+}
+
+// delay returns how long to wait after the given failed attempt:
+// the server-provided delay if err carries one, the jittered backoff otherwise.
+func (c *config) delay(attempt int, err error) time.Duration {
+	if d, ok := errx.RetryAfterOf(err); ok {
+		return d
+	}
+	d := c.initialDelay
+	for i := 1; i < attempt && d < c.maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, c.maxDelay)
+	if c.jitter > 0 {
+		d -= time.Duration(rand.Float64() * c.jitter * float64(d)) //nolint:gosec // jitter needs no crypto randomness
+	}
+	return d
+}
@@ -0,0 +1,223 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mickamy/errx"
+	"github.com/mickamy/errx/retry"
+)
+
+// fakeClock records the requested delays and fires immediately.
+type fakeClock struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.delays = append(c.delays, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+	return ch
+}
+
+// blockingClock never fires.
+type blockingClock struct{}
+
+func (blockingClock) After(time.Duration) <-chan time.Time { return nil }
+
+var errUnavailable = errx.NewSentinel("unavailable", errx.Unavailable)
+
+func attemptsOf(t *testing.T, err error) int64 {
+	t.Helper()
+	for _, a := range errx.Fields(err) {
+		if a.Key == "attempts" {
+			return a.Value.Int64()
+		}
+	}
+	t.Fatalf("attempts field missing in %v", errx.Fields(err))
+	return 0
+}
+
+func TestDo_Success(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{}
+	calls := 0
+	err := retry.Do(context.Background(), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errx.Wrap(errUnavailable)
+		}
+		return nil
+	}, retry.WithClock(clock), retry.WithJitter(0))
+	if err != nil {
+		t.Fatalf("Do() = %v, want nil", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}
+	if len(clock.delays) != len(want) || clock.delays[0] != want[0] || clock.delays[1] != want[1] {
+		t.Errorf("delays = %v, want %v", clock.delays, want)
+	}
+}
+
+func TestDo_ExhaustsAttempts(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{}
+	opts := []retry.Option{
+		retry.WithClock(clock), retry.WithJitter(0), retry.WithMaxAttempts(6), retry.WithBackoff(time.Second, 5*time.Second),
+	}
+	err := retry.Do(context.Background(), func(context.Context) error {
+		return errx.Wrap(errUnavailable)
+	}, opts...)
+
+	var ex *errx.Error
+	if !errors.As(err, &ex) {
+		t.Fatalf("Do() = %T, want *errx.Error", err)
+	}
+	if !errors.Is(err, errUnavailable) {
+		t.Error("the last attempt's error should be wrapped")
+	}
+	if got := attemptsOf(t, err); got != 6 {
+		t.Errorf("attempts = %d, want 6", got)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, d := range want {
+		if i >= len(clock.delays) || clock.delays[i] != d {
+			t.Fatalf("delays = %v, want %v", clock.delays, want)
+		}
+	}
+}
+
+func TestDo_NonRetryable(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := retry.Do(context.Background(), func(context.Context) error {
+		calls++
+		return errx.New("bad input").WithCode(errx.InvalidArgument)
+	}, retry.WithClock(&fakeClock{}))
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if errx.CodeOf(err) != errx.InvalidArgument {
+		t.Errorf("CodeOf() = %q, want %q", errx.CodeOf(err), errx.InvalidArgument)
+	}
+	if got := attemptsOf(t, err); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestDo_ServerDelay(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{}
+	calls := 0
+	_ = retry.Do(context.Background(), func(context.Context) error {
+		calls++
+		if calls == 1 {
+			// e.g. a RetryInfo detail restored by gerr.FromStatus
+			return errx.New("rate limited").WithCode(errx.ResourceExhausted).WithDetails(errx.RetryInfo(7 * time.Second))
+		}
+		return errx.New("busy").WithRetryAfter(3 * time.Second)
+	}, retry.WithClock(clock), retry.WithMaxAttempts(3))
+
+	want := []time.Duration{7 * time.Second, 3 * time.Second}
+	if len(clock.delays) != 2 || clock.delays[0] != want[0] || clock.delays[1] != want[1] {
+		t.Errorf("delays = %v, want %v (server delays, no jitter)", clock.delays, want)
+	}
+}
+
+func TestDo_Jitter(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{}
+	opts := []retry.Option{
+		retry.WithClock(clock), retry.WithMaxAttempts(50), retry.WithBackoff(time.Second, time.Second), retry.WithJitter(0.5),
+	}
+	_ = retry.Do(context.Background(), func(context.Context) error {
+		return errx.Wrap(errUnavailable)
+	}, opts...)
+
+	distinct := map[time.Duration]bool{}
+	for _, d := range clock.delays {
+		if d < 500*time.Millisecond || d > time.Second {
+			t.Errorf("delay %v outside [500ms, 1s]", d)
+		}
+		distinct[d] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("delays = %v, want jittered values", clock.delays)
+	}
+}
+
+func TestDo_RetryIf(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	_ = retry.Do(context.Background(), func(context.Context) error {
+		calls++
+		return errors.New("plain")
+	}, retry.WithClock(&fakeClock{}), retry.WithMaxAttempts(3), retry.WithRetryIf(func(error) bool { return true }))
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDo_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := retry.Do(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return errx.Wrap(errUnavailable)
+	}, retry.WithClock(blockingClock{}))
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errUnavailable) {
+		t.Errorf("Do() = %v, want both the context error and the last error", err)
+	}
+	if got := errx.CodeOf(err); got != errx.Canceled {
+		t.Errorf("CodeOf() = %q, want %q (not the last error's code)", got, errx.Canceled)
+	}
+	if got := attemptsOf(t, err); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+
+	err = retry.Do(ctx, func(context.Context) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do(canceled ctx) = %v, want context.Canceled", err)
+	}
+	if got := attemptsOf(t, err); got != 0 {
+		t.Errorf("attempts = %d, want 0", got)
+	}
+}
+
+func TestDo_DeadlineExceeded(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := retry.Do(ctx, func(context.Context) error {
+		return errx.Wrap(errUnavailable)
+	}, retry.WithClock(blockingClock{}))
+
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errUnavailable) {
+		t.Errorf("Do() = %v, want both the context error and the last error", err)
+	}
+	if got := errx.CodeOf(err); got != errx.DeadlineExceeded {
+		t.Errorf("CodeOf() = %q, want %q", got, errx.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,90 @@
+package errx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mickamy/errx"
+)
+
+// tempError is a foreign error implementing the net.Error-style Temporary method.
+type tempError struct{ temporary bool }
+
+func (e tempError) Error() string   { return "temp" }
+func (e tempError) Temporary() bool { return e.temporary }
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"uncoded", errors.New("boom"), false},
+		{"unavailable", errx.New("db down").WithCode(errx.Unavailable), true},
+		{"aborted", errx.New("conflict").WithCode(errx.Aborted), true},
+		{"resource exhausted", errx.New("rate limited").WithCode(errx.ResourceExhausted), true},
+		{"internal", errx.New("bug").WithCode(errx.Internal), false},
+		{"sentinel", errx.Wrap(errx.NewSentinel("down", errx.Unavailable)), true},
+		{"override to false", errx.New("down").WithCode(errx.Unavailable).WithRetryable(false), false},
+		{"override to true", errx.New("bug").WithCode(errx.Internal).WithRetryable(true), true},
+		{"outermost override wins", errx.Wrap(errx.New("x").WithRetryable(true)).WithRetryable(false), false},
+		{"retry after", errx.New("busy").WithRetryAfter(time.Second), true},
+		{"foreign temporary", fmt.Errorf("dial: %w", tempError{temporary: true}), true},
+		{"foreign not temporary", errx.Wrap(tempError{}), false},
+		{"code decides before foreign temporary", errx.Wrap(tempError{}).WithCode(errx.Unavailable), true},
+		{"wrapped deadline exceeded", errx.Wrap(context.DeadlineExceeded), false},
+		{"wrapped canceled", fmt.Errorf("query: %w", context.Canceled), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errx.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterOf(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := errx.RetryAfterOf(errx.New("fail")); ok {
+		t.Error("RetryAfterOf should report false without a hint")
+	}
+	err := errx.Wrap(errx.New("busy").WithRetryAfter(3 * time.Second))
+	if d, ok := errx.RetryAfterOf(err); !ok || d != 3*time.Second {
+		t.Errorf("RetryAfterOf() = %v, %v, want 3s, true", d, ok)
+	}
+	err = errx.New("busy").WithDetails(errx.RetryInfo(2 * time.Second))
+	if d, ok := errx.RetryAfterOf(err); !ok || d != 2*time.Second {
+		t.Errorf("RetryAfterOf() from RetryInfo = %v, %v, want 2s, true", d, ok)
+	}
+}
+
+func TestError_NetErrorInterfaces(t *testing.T) {
+	t.Parallel()
+
+	err := errx.New("timeout").WithCode(errx.DeadlineExceeded)
+	var ne interface {
+		Temporary() bool
+		Timeout() bool
+	} = err
+	if !ne.Timeout() {
+		t.Error("Timeout() should be true for DeadlineExceeded")
+	}
+	if ne.Temporary() {
+		t.Error("Temporary() should be false for DeadlineExceeded by default")
+	}
+	if !errx.New("down").WithCode(errx.Unavailable).Temporary() {
+		t.Error("Temporary() should be true for Unavailable")
+	}
+
+	var _ net.Error = err
+}
@@ -2,9 +2,11 @@ package errx
 
 // SentinelError is an immutable error value intended for use as a package-level sentinel.
 // It carries a fixed message and code, and supports errors.Is matching by identity.
+// A sentinel created with [NewChildSentinel] also matches its parent and every ancestor.
 type SentinelError struct {
-	msg  string
-	code Code
+	msg    string
+	code   Code
+	parent *SentinelError
 }
 
 // NewSentinel creates a new sentinel error with the given message and code.
@@ -12,8 +14,37 @@ func NewSentinel(msg string, code Code) *SentinelError {
 	return &SentinelError{msg: msg, code: code}
 }
 
+// NewChildSentinel creates a sentinel that is a more specific case of parent:
+// errors.Is matches both the child and parent (and its ancestors).
+// The code is inherited from parent if code is empty.
+//
+//	var ErrNotFound = errx.NewSentinel("not found", errx.NotFound)
+//	var ErrUserNotFound = errx.NewChildSentinel(ErrNotFound, "user not found", "")
+//
+//	errors.Is(errx.Wrap(ErrUserNotFound), ErrNotFound) // true
+func NewChildSentinel(parent *SentinelError, msg string, code Code) *SentinelError {
+	if code == "" && parent != nil {
+		code = parent.code
+	}
+	return &SentinelError{msg: msg, code: code, parent: parent}
+}
+
 // Error implements the error interface.
 func (s *SentinelError) Error() string { return s.msg }
 
 // Code implements the Coder interface.
 func (s *SentinelError) Code() Code { return s.code }
+
+// Parent returns the sentinel s was derived from, or nil.
+func (s *SentinelError) Parent() *SentinelError { return s.parent }
+
+// Is reports whether target is an ancestor of s, so that errors.Is matches
+// the parents of a sentinel created with [NewChildSentinel].
+func (s *SentinelError) Is(target error) bool {
+	for p := s.parent; p != nil; p = p.parent {
+		if p == target { //nolint:errorlint // sentinels match by identity
+			return true
+		}
+	}
+	return false
+}
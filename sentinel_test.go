@@ -75,3 +75,44 @@ func TestSentinel_CodeOverride(t *testing.T) {
 		t.Errorf("Code() = %q, want %q (outer should override)", err.Code(), errx.Unavailable)
 	}
 }
+
+func TestChildSentinel(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := errx.NewSentinel("not found", errx.NotFound)
+	errUserNotFound := errx.NewChildSentinel(errNotFound, "user not found", "")
+	errAdminNotFound := errx.NewChildSentinel(errUserNotFound, "admin not found", errx.PermissionDenied)
+	errOther := errx.NewSentinel("not found", errx.NotFound)
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"child matches itself", errUserNotFound, errUserNotFound, true},
+		{"child matches parent", errUserNotFound, errNotFound, true},
+		{"wrapped child matches parent", errx.Wrap(errUserNotFound, "id", 1), errNotFound, true},
+		{"grandchild matches grandparent", errx.Wrapf(errAdminNotFound, "get"), errNotFound, true},
+		{"parent does not match child", errNotFound, errUserNotFound, false},
+		{"unrelated sentinel", errUserNotFound, errOther, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if errUserNotFound.Code() != errx.NotFound {
+		t.Errorf("inherited Code() = %q, want %q", errUserNotFound.Code(), errx.NotFound)
+	}
+	if errAdminNotFound.Code() != errx.PermissionDenied {
+		t.Errorf("overridden Code() = %q, want %q", errAdminNotFound.Code(), errx.PermissionDenied)
+	}
+	if errAdminNotFound.Parent() != errUserNotFound || errNotFound.Parent() != nil {
+		t.Error("Parent() should return the sentinel passed to NewChildSentinel")
+	}
+}
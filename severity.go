@@ -0,0 +1,137 @@
+package errx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Severity classifies how serious an error is.
+// The zero value means the severity is not set.
+type Severity int8
+
+// Severities, from least to most serious.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityCritical
+)
+
+// LevelCritical is the slog level of [SeverityCritical].
+const LevelCritical = slog.LevelError + 4
+
+// String returns the lowercase name of the severity, or "" if it is not set.
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return ""
+	}
+}
+
+// Level returns the slog level of the severity.
+// An unset severity maps to slog.LevelError.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarn:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return LevelCritical
+	default:
+		return slog.LevelError
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for v := SeverityDebug; v <= SeverityCritical; v++ {
+		if v.String() == string(text) {
+			*s = v
+			return nil
+		}
+	}
+	if len(text) == 0 {
+		*s = 0
+		return nil
+	}
+	return fmt.Errorf("errx: unknown severity %q", text)
+}
+
+// WithSeverity returns a copy of the error with the given severity,
+// overriding the default derived from its code.
+func (e *Error) WithSeverity(s Severity) *Error {
+	cp := *e
+	cp.severity = s
+	return &cp
+}
+
+// SeverityOf returns the severity of err: the outermost severity set with
+// [Error.WithSeverity] in the chain, otherwise the [CodeInfo.Severity] of its code
+// (inherited from the nearest ancestor that sets one), otherwise [SeverityError].
+// Client errors such as NotFound and InvalidArgument default to [SeverityInfo],
+// server faults to [SeverityError] and DataLoss to [SeverityCritical].
+// Returns 0 if err is nil.
+func SeverityOf(err error) Severity {
+	if err == nil {
+		return 0
+	}
+	var s Severity
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok && ex.severity != 0 { //nolint:errorlint // walk visits every layer
+			s = ex.severity
+			return false
+		}
+		return true
+	})
+	if s != 0 {
+		return s
+	}
+	if s := inheritedInfo(CodeOf(err), func(info CodeInfo) Severity { return info.Severity }); s != 0 {
+		return s
+	}
+	return SeverityError
+}
+
+// LevelOf returns the slog level err should be logged at (see [SeverityOf]).
+func LevelOf(err error) slog.Level {
+	return SeverityOf(err).Level()
+}
+
+// Log logs err with logger at the level of [LevelOf], with err as the "error" attribute
+// (see [SlogAttr]) followed by args. A nil logger means slog.Default().
+// Does nothing if err is nil.
+//
+//	errx.Log(ctx, logger, err, "request failed", "method", r.Method)
+func Log(ctx context.Context, logger *slog.Logger, err error, msg string, args ...any) {
+	if err == nil {
+		return
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level := LevelOf(err)
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, msg, append([]any{SlogAttr(err)}, args...)...)
+}
@@ -0,0 +1,121 @@
+package errx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func TestSeverityOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want errx.Severity
+	}{
+		{"nil", nil, 0},
+		{"uncoded", errors.New("boom"), errx.SeverityError},
+		{"client error", errx.New("no user").WithCode(errx.NotFound), errx.SeverityInfo},
+		{"child code", errx.New("no user").WithCode("not_found.user"), errx.SeverityInfo},
+		{"server fault", errx.New("boom").WithCode(errx.Internal), errx.SeverityError},
+		{"data loss", errx.New("corrupt").WithCode(errx.DataLoss), errx.SeverityCritical},
+		{"registered code", errx.New("quota").WithCode(codeQuotaExceeded), errx.SeverityWarn},
+		{
+			"explicit",
+			errx.New("no user").WithCode(errx.NotFound).WithSeverity(errx.SeverityError),
+			errx.SeverityError,
+		},
+		{
+			"outermost explicit wins",
+			fmt.Errorf("x: %w", errx.Wrap(errx.New("a").WithSeverity(errx.SeverityDebug)).WithSeverity(errx.SeverityWarn)),
+			errx.SeverityWarn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errx.SeverityOf(tt.err); got != tt.want {
+				t.Errorf("SeverityOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		severity errx.Severity
+		want     slog.Level
+	}{
+		{errx.SeverityDebug, slog.LevelDebug},
+		{errx.SeverityInfo, slog.LevelInfo},
+		{errx.SeverityWarn, slog.LevelWarn},
+		{errx.SeverityError, slog.LevelError},
+		{errx.SeverityCritical, errx.LevelCritical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			t.Parallel()
+			if got := errx.LevelOf(errx.New("x").WithSeverity(tt.severity)); got != tt.want {
+				t.Errorf("LevelOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverity_Text(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(errx.SeverityWarn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"warn"` {
+		t.Errorf("Marshal() = %s, want %q", b, `"warn"`)
+	}
+	var s errx.Severity
+	if err := json.Unmarshal([]byte(`"critical"`), &s); err != nil || s != errx.SeverityCritical {
+		t.Errorf("Unmarshal() = %v, %v", s, err)
+	}
+	if err := json.Unmarshal([]byte(`"fatal"`), &s); err == nil {
+		t.Error("Unmarshal() of an unknown severity should fail")
+	}
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	errx.Log(t.Context(), logger, errx.New("no user").WithCode(errx.NotFound), "request failed", "path", "/users/1")
+	errx.Log(t.Context(), logger, nil, "not logged")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logged %d lines, want 1:\n%s", len(lines), buf.String())
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["level"] != "INFO" {
+		t.Errorf("level = %v, want INFO", m["level"])
+	}
+	if m["path"] != "/users/1" {
+		t.Errorf("path = %v, want /users/1", m["path"])
+	}
+	if errObj, ok := m["error"].(map[string]any); !ok || errObj["code"] != "not_found" {
+		t.Errorf("error = %v", m["error"])
+	}
+}
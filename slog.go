@@ -1,59 +1,157 @@
 package errx
 
-import "log/slog"
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// SlogOption configures the attributes built by [SlogAttr] and [Error.LogValue].
+type SlogOption func(*slogConfig)
+
+type slogConfig struct {
+	stacks      bool
+	unredacted  bool
+	fingerprint []FingerprintOption // nil unless WithFingerprint is given
+}
+
+// WithAllStacks emits every stack of the chain (see [StacksOf]) under the "stacks" key,
+// outermost first, each trimmed of the frames it shares with its enclosing stack.
+func WithAllStacks() SlogOption {
+	return func(c *slogConfig) {
+		c.stacks = true
+	}
+}
+
+// WithFingerprint adds the [Fingerprint] of the error under the "fingerprint" key,
+// computed with the given options.
+func WithFingerprint(opts ...FingerprintOption) SlogOption {
+	return func(c *slogConfig) {
+		c.fingerprint = append([]FingerprintOption{}, opts...)
+	}
+}
+
+var defaultSlogOptions atomic.Pointer[[]SlogOption]
+
+// SetDefaultSlogOptions sets the options used by [Error.LogValue],
+// which cannot take options, and applied by [SlogAttr] before its own options.
+// Must be called at program initialization (e.g. in init()), before logging.
+func SetDefaultSlogOptions(opts ...SlogOption) {
+	cp := append([]SlogOption(nil), opts...)
+	defaultSlogOptions.Store(&cp)
+}
+
+func newSlogConfig(opts []SlogOption) slogConfig {
+	var c slogConfig
+	if defaults := defaultSlogOptions.Load(); defaults != nil {
+		for _, o := range *defaults {
+			o(&c)
+		}
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
 
 // LogValue implements slog.LogValuer, allowing *Error to be logged directly as a structured value.
 // Fields are collected from the entire error chain (outermost first).
+// [Secret] values and fields with keys registered with [RegisterSensitiveKeys] are redacted.
+// Options set with [SetDefaultSlogOptions] apply.
 func (e *Error) LogValue() slog.Value {
+	cfg := newSlogConfig(nil)
 	attrs := make([]slog.Attr, 0, 4)
-	attrs = append(attrs, slog.String("msg", e.Error()))
+	attrs = append(attrs, slog.String("msg", cfg.message(e)))
 	if c := e.Code(); c != "" {
 		attrs = append(attrs, slog.String("code", c.String()))
 	}
-	attrs = append(attrs, Fields(e)...)
-	if s := StackOf(e); s != nil {
-		frames := s.Frames()
-		if len(frames) > 0 {
-			f := frames[0]
-			attrs = append(attrs, slog.Group("caller",
-				slog.String("function", f.Function),
-				slog.String("file", f.File),
-				slog.Int("line", f.Line),
-			))
-		}
-	}
+	attrs = appendOpsAttr(attrs, e)
+	attrs = appendFingerprintAttr(attrs, e, cfg)
+	attrs = appendFieldAttrs(attrs, e, cfg)
+	attrs = appendStackAttrs(attrs, e, cfg)
 	return slog.GroupValue(attrs...)
 }
 
 // SlogAttr builds a slog.Attr from the entire error chain.
 // Fields are collected outermost-first; code is taken from the first Coder in the chain.
-func SlogAttr(err error) slog.Attr {
+// Secrets are redacted as in [Error.LogValue] unless [Unredacted] is given.
+// Options set with [SetDefaultSlogOptions] apply before opts.
+func SlogAttr(err error, opts ...SlogOption) slog.Attr {
 	if err == nil {
 		return slog.Attr{}
 	}
 
+	cfg := newSlogConfig(opts)
 	attrs := make([]slog.Attr, 0, 4)
-	attrs = append(attrs, slog.String("msg", err.Error()))
+	attrs = append(attrs, slog.String("msg", cfg.message(err)))
 
 	if c := CodeOf(err); c != "" {
 		attrs = append(attrs, slog.String("code", c.String()))
 	}
 
-	attrs = append(attrs, Fields(err)...)
+	attrs = appendOpsAttr(attrs, err)
+	attrs = appendFingerprintAttr(attrs, err, cfg)
+	attrs = appendFieldAttrs(attrs, err, cfg)
+	attrs = appendStackAttrs(attrs, err, cfg)
 
-	if s := StackOf(err); s != nil {
-		frames := s.Frames()
-		if len(frames) > 0 {
-			f := frames[0]
-			attrs = append(attrs, slog.Group("caller",
-				slog.String("function", f.Function),
-				slog.String("file", f.File),
-				slog.Int("line", f.Line),
-			))
+	return slog.Attr{Key: "error", Value: slog.GroupValue(attrs...)}
+}
+
+// message returns the message of err, with secrets revealed if configured.
+func (c slogConfig) message(err error) string {
+	if c.unredacted {
+		return unredactedError(err)
+	}
+	return err.Error()
+}
+
+// appendFieldAttrs appends the fields of the chain, redacting sensitive keys
+// or revealing secrets as configured.
+func appendFieldAttrs(attrs []slog.Attr, err error, c slogConfig) []slog.Attr {
+	for _, a := range Fields(err) {
+		if c.unredacted {
+			attrs = append(attrs, revealAttr(a))
+		} else {
+			attrs = append(attrs, redactAttr(a))
 		}
 	}
+	return attrs
+}
 
-	return slog.Attr{Key: "error", Value: slog.GroupValue(attrs...)}
+// appendOpsAttr appends the "ops" attribute holding the [OpsOf] path, if any.
+func appendOpsAttr(attrs []slog.Attr, err error) []slog.Attr {
+	if ops := OpsOf(err); len(ops) > 0 {
+		attrs = append(attrs, slog.Any("ops", ops))
+	}
+	return attrs
+}
+
+// appendFingerprintAttr appends the "fingerprint" attribute if [WithFingerprint] was given.
+func appendFingerprintAttr(attrs []slog.Attr, err error, c slogConfig) []slog.Attr {
+	if c.fingerprint == nil {
+		return attrs
+	}
+	return append(attrs, slog.String("fingerprint", Fingerprint(err, c.fingerprint...)))
+}
+
+// appendStackAttrs appends the "caller" group built from the first stack of the chain
+// and, if configured, the "stacks" attribute.
+func appendStackAttrs(attrs []slog.Attr, err error, c slogConfig) []slog.Attr {
+	s := StackOf(err)
+	if s == nil {
+		return attrs
+	}
+	if frames := s.symbolized(); len(frames) > 0 {
+		f := frames[0]
+		attrs = append(attrs, slog.Group("caller",
+			slog.String("function", f.Function),
+			slog.String("file", f.File),
+			slog.Int("line", f.Line),
+		))
+	}
+	if c.stacks {
+		attrs = append(attrs, slog.Any("stacks", trimmedStacks(err, nil)))
+	}
+	return attrs
 }
 
 // Ensure *Error implements slog.LogValuer at compile time.
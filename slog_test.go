@@ -146,3 +146,52 @@ func TestLogValue_WithStack(t *testing.T) {
 		t.Error("caller.function should be populated")
 	}
 }
+
+func TestSlogAttr_WithAllStacks(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan error)
+	go func() { ch <- errx.New("worker failed").WithStack() }()
+	err := errx.Wrap(<-ch).WithStack()
+
+	errObj := logSlogAttr(t, errx.SlogAttr(err, errx.WithAllStacks()))
+	stacks, ok := errObj["stacks"].([]any)
+	if !ok || len(stacks) != 2 {
+		t.Fatalf("stacks = %v, want 2 stacks", errObj["stacks"])
+	}
+	if _, ok := errObj["caller"].(map[string]any); !ok {
+		t.Error("caller group should still be present")
+	}
+
+	if _, ok := logSlogAttr(t, errx.SlogAttr(err))["stacks"]; ok {
+		t.Error("stacks should be omitted without WithAllStacks")
+	}
+}
+
+func TestSetDefaultSlogOptions(t *testing.T) { //nolint:paralleltest // sets global slog options
+	errx.SetDefaultSlogOptions(errx.WithAllStacks())
+	t.Cleanup(func() { errx.SetDefaultSlogOptions() })
+
+	err := errx.New("fail").WithStack()
+	if _, ok := logSlogAttr(t, slog.Any("error", err))["stacks"].([]any); !ok {
+		t.Error("LogValue should emit stacks with the default options")
+	}
+}
+
+// logSlogAttr logs a with a JSON handler and returns the decoded attribute value.
+func logSlogAttr(t *testing.T, a slog.Attr) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("msg", a)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("failed to parse JSON: %v\nbody: %s", err, buf.String())
+	}
+	errObj, ok := m[a.Key].(map[string]any)
+	if !ok {
+		t.Fatalf("expected %s to be an object, got: %v", a.Key, m[a.Key])
+	}
+	return errObj
+}
@@ -1,13 +1,25 @@
 package errx
 
 import (
-	"errors"
+	"math/rand/v2"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-// Stack holds captured stack frames.
+// defaultStackDepth is the maximum number of frames captured when
+// [StackPolicy] does not set a depth.
+const defaultStackDepth = 32
+
+// Stack holds a captured call stack.
+// Only program counters are recorded; they are symbolized the first time
+// the frames are needed (e.g. by [Stack.Frames] or when the error is logged).
 type Stack struct {
+	pcs    []uintptr
+	caller [1]uintptr // backing array of pcs for [Error.WithCaller]
+	once   sync.Once
 	frames []Frame
 }
 
@@ -16,16 +28,31 @@ func (s *Stack) Frames() []Frame {
 	if s == nil {
 		return nil
 	}
-	cp := make([]Frame, len(s.frames))
-	copy(cp, s.frames)
+	frames := s.symbolized()
+	cp := make([]Frame, len(frames))
+	copy(cp, frames)
 	return cp
 }
 
+// symbolized returns the frames of s, resolving its program counters on first use.
+// The result is shared and must not be modified.
+func (s *Stack) symbolized() []Frame {
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		if s.pcs != nil {
+			s.frames = symbolize(s.pcs)
+		}
+	})
+	return s.frames
+}
+
 // Frame represents a single stack frame.
 type Frame struct {
-	Function string
-	File     string
-	Line     int
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
 }
 
 // WithStack returns a copy of the error with a captured stack trace.
@@ -35,33 +62,193 @@ func (e *Error) WithStack() *Error {
 	return &cp
 }
 
+// WithCaller returns a copy of the error that records only the frame of its caller.
+// It is a cheaper alternative to [Error.WithStack] when only the place where
+// the error was created matters.
+func (e *Error) WithCaller() *Error {
+	cp := *e
+	s := &Stack{}
+	n := runtime.Callers(2, s.caller[:]) // skip runtime.Callers and WithCaller
+	s.pcs = s.caller[:n]
+	cp.stack = s
+	return &cp
+}
+
 // StackOf walks the error chain and returns the first Stack found, or nil.
+// Errors with multiple causes are walked depth-first.
 func StackOf(err error) *Stack {
+	var s *Stack
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok && ex.stack != nil { //nolint:errorlint // walk visits every layer
+			s = ex.stack
+			return false
+		}
+		return true
+	})
+	return s
+}
+
+// StackPolicy controls when stacks are captured automatically,
+// without an explicit call to [Error.WithStack].
+//
+//	// capture a stack for every server fault
+//	errx.SetStackPolicy(errx.StackPolicy{Codes: []errx.Code{errx.Internal, errx.Unknown, errx.DataLoss}})
+//
+//	// capture a stack for every error (e.g. in dev builds)
+//	errx.SetStackPolicy(errx.StackPolicy{Always: true})
+type StackPolicy struct {
+	// Always captures a stack in every [New], [Wrap] and [Wrapf].
+	Always bool
+
+	// Codes captures a stack when an error gets one of these codes or a descendant, either from
+	// [Error.WithCode] or from the cause passed to [Wrap] and [Wrapf].
+	Codes []Code
+
+	// SampleRate is the fraction of automatic captures that are kept, in (0, 1].
+	// Zero (or any value outside that range) keeps every capture.
+	// Explicit [Error.WithStack] calls are never sampled.
+	SampleRate float64
+
+	// Depth is the maximum number of frames captured, including by [Error.WithStack].
+	// Zero means 32.
+	Depth int
+}
+
+var stackPolicy atomic.Pointer[StackPolicy]
+
+// SetStackPolicy installs the automatic stack capture policy.
+// The zero StackPolicy disables automatic capture (the default).
+// Errors that already carry a stack in their cause chain never capture another one.
+// Must be called at program initialization (e.g. in init()), before serving requests.
+func SetStackPolicy(p StackPolicy) {
+	p.Codes = slices.Clone(p.Codes)
+	if !p.Always && len(p.Codes) == 0 && p.Depth == 0 {
+		stackPolicy.Store(nil)
+		return
+	}
+	stackPolicy.Store(&p)
+}
+
+// autoStack captures a stack for an error created by [New], [Wrap], [Wrapf] or a [Template]
+// if the installed [StackPolicy] asks for one. cause is the wrapped error, if any;
+// code is the error's code, or "" to take the code of cause.
+func autoStack(cause error, code Code) *Stack {
+	p := stackPolicy.Load()
+	if p == nil {
+		return nil
+	}
+	if !p.Always {
+		if len(p.Codes) == 0 {
+			return nil
+		}
+		if code == "" && cause != nil {
+			code = CodeOf(cause)
+		}
+		if !code.in(p.Codes) {
+			return nil
+		}
+	}
+	if !p.sample(cause) {
+		return nil
+	}
+	return captureStack(3) // skip captureStack, autoStack and the constructor
+}
+
+// codeStack returns the stack of an error that is given code c by [Error.WithCode],
+// capturing one if it has none and the installed [StackPolicy] lists c.
+func codeStack(e *Error, c Code) *Stack {
+	p := stackPolicy.Load()
+	if p == nil || e.stack != nil || !c.in(p.Codes) {
+		return e.stack
+	}
+	if !p.sample(e.cause) {
+		return nil
+	}
+	return captureStack(3) // skip captureStack, codeStack and WithCode
+}
+
+// sample reports whether an automatic capture for an error wrapping cause is kept:
+// causes that already carry a stack never get another one, the rest are sampled.
+func (p *StackPolicy) sample(cause error) bool {
+	if cause != nil && StackOf(cause) != nil {
+		return false
+	}
+	return p.SampleRate <= 0 || p.SampleRate >= 1 ||
+		rand.Float64() < p.SampleRate //nolint:gosec // sampling needs no crypto randomness
+}
+
+// StacksOf returns every Stack in the error chain, outermost first.
+// Errors with multiple causes are walked depth-first.
+// An error wrapped again with [Error.WithStack] after crossing a goroutine
+// (e.g. a channel or errgroup) thus yields both the original trace and the hand-off.
+func StacksOf(err error) []*Stack {
+	var stacks []*Stack
+	walk(err, func(err error) bool {
+		if ex, ok := err.(*Error); ok && ex.stack != nil { //nolint:errorlint // walk visits every layer
+			stacks = append(stacks, ex.stack)
+		}
+		return true
+	})
+	return stacks
+}
+
+// Trim returns the frames of s without the trailing frames it shares with enclosing,
+// the stack of an outer layer of the same chain. Those frames (the callers both
+// stacks passed through) are already part of the enclosing stack.
+// All frames are returned if enclosing is nil.
+func (s *Stack) Trim(enclosing *Stack) []Frame {
+	frames := s.Frames()
+	outer := enclosing.symbolized()
+	n := 0
+	for n < len(frames) && n < len(outer) && frames[len(frames)-1-n] == outer[len(outer)-1-n] {
+		n++
+	}
+	return frames[:len(frames)-n]
+}
+
+// trimmedStacks returns every stack in the chain of err, outermost first,
+// each trimmed of the frames it shares with the nearest stack enclosing it.
+func trimmedStacks(err error, enclosing *Stack) [][]Frame {
+	var stacks [][]Frame
 	for err != nil {
-		var ex *Error
-		if errors.As(err, &ex) {
-			if ex.stack != nil {
-				return ex.stack
-			}
-			err = ex.cause
-		} else {
-			break
+		if ex, ok := err.(*Error); ok && ex.stack != nil { //nolint:errorlint // inspecting a single layer
+			stacks = append(stacks, ex.stack.Trim(enclosing))
+			enclosing = ex.stack
 		}
+		next, branches := unwrap(err)
+		for _, b := range branches {
+			stacks = append(stacks, trimmedStacks(b, enclosing)...)
+		}
+		err = next
 	}
-	return nil
+	return stacks
 }
 
 // captureStack captures the call stack, skipping the given number of frames
 // (callers above captureStack itself).
 func captureStack(skip int) *Stack {
-	var pcs [32]uintptr
-	n := runtime.Callers(skip+1, pcs[:]) // +1 for runtime.Callers itself
-	if n == 0 {
-		return &Stack{}
+	pcs := make([]uintptr, stackDepth())
+	n := runtime.Callers(skip+1, pcs) // +1 for runtime.Callers itself
+	return &Stack{pcs: pcs[:n]}
+}
+
+// stackDepth returns the maximum number of frames to capture.
+func stackDepth() int {
+	if p := stackPolicy.Load(); p != nil && p.Depth > 0 {
+		return p.Depth
 	}
+	return defaultStackDepth
+}
 
-	rframes := runtime.CallersFrames(pcs[:n])
-	frames := make([]Frame, 0, n)
+// symbolize resolves program counters to frames, skipping runtime internals.
+// Inlined calls expand to several frames; at most len(pcs) frames are returned
+// so that the configured depth holds.
+func symbolize(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	rframes := runtime.CallersFrames(pcs)
+	frames := make([]Frame, 0, len(pcs))
 	for {
 		f, more := rframes.Next()
 		// Skip runtime internals.
@@ -76,9 +263,9 @@ func captureStack(skip int) *Stack {
 			File:     f.File,
 			Line:     f.Line,
 		})
-		if !more {
+		if !more || len(frames) == len(pcs) {
 			break
 		}
 	}
-	return &Stack{frames: frames}
+	return frames
 }
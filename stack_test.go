@@ -1,7 +1,9 @@
 package errx_test
 
 import (
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/mickamy/errx"
@@ -76,3 +78,177 @@ func TestStack_Frames_Nil(t *testing.T) {
 		t.Error("nil Stack.Frames() should return nil")
 	}
 }
+
+func TestStackPolicy_Codes(t *testing.T) { //nolint:paralleltest // installs a global policy
+	errx.SetStackPolicy(errx.StackPolicy{Codes: []errx.Code{errx.Internal}})
+	t.Cleanup(func() { errx.SetStackPolicy(errx.StackPolicy{}) })
+
+	if errx.StackOf(errx.New("bad input").WithCode(errx.InvalidArgument)) != nil {
+		t.Error("codes outside the policy should not capture a stack")
+	}
+
+	err := errx.New("boom").WithCode(errx.Internal)
+	s := errx.StackOf(err)
+	if s == nil {
+		t.Fatal("WithCode(Internal) should capture a stack")
+	}
+	if top := s.Frames()[0]; !strings.Contains(top.Function, "TestStackPolicy_Codes") {
+		t.Errorf("top frame function = %q, want containing %q", top.Function, "TestStackPolicy_Codes")
+	}
+
+	sentinel := errx.NewSentinel("db down", errx.Internal)
+	wrapped := errx.Wrap(sentinel, "table", "users")
+	if errx.StackOf(wrapped) == nil {
+		t.Error("Wrap of an Internal cause should capture a stack")
+	}
+	top := errx.StackOf(errx.Wrapf(sentinel, "query")).Frames()[0]
+	if !strings.Contains(top.Function, "TestStackPolicy_Codes") {
+		t.Errorf("Wrapf top frame function = %q, want containing %q", top.Function, "TestStackPolicy_Codes")
+	}
+
+	if s := errx.StackOf(errx.Define(errx.Internal, "boom").New()); s == nil ||
+		!strings.Contains(s.Frames()[0].Function, "TestStackPolicy_Codes") {
+		t.Error("templates with a code in the policy should capture a stack at the call site")
+	}
+
+	// The innermost stack is kept; wrappers do not capture another one.
+	if outer := errx.Wrap(wrapped).WithCode(errx.Internal); errx.StackOf(outer) != errx.StackOf(wrapped) {
+		t.Error("wrapping an error that already has a stack should not capture again")
+	}
+}
+
+func TestStackPolicy_Always(t *testing.T) { //nolint:paralleltest // installs a global policy
+	errx.SetStackPolicy(errx.StackPolicy{Always: true, Depth: 2})
+	t.Cleanup(func() { errx.SetStackPolicy(errx.StackPolicy{}) })
+
+	s := errx.StackOf(errx.New("fail"))
+	if s == nil {
+		t.Fatal("New should capture a stack with Always")
+	}
+	if n := len(s.Frames()); n == 0 || n > 2 {
+		t.Errorf("frames = %d, want 1..2 (Depth)", n)
+	}
+	if n := len(errx.StackOf(errx.New("fail").WithStack()).Frames()); n > 2 {
+		t.Errorf("WithStack frames = %d, want at most 2 (Depth)", n)
+	}
+}
+
+func TestStackPolicy_SampleRate(t *testing.T) { //nolint:paralleltest // installs a global policy
+	errx.SetStackPolicy(errx.StackPolicy{Always: true, SampleRate: 0.5})
+	t.Cleanup(func() { errx.SetStackPolicy(errx.StackPolicy{}) })
+
+	captured := 0
+	for range 1000 {
+		if errx.StackOf(errx.New("fail")) != nil {
+			captured++
+		}
+	}
+	if captured == 0 || captured == 1000 {
+		t.Errorf("captured %d of 1000 stacks, want some but not all", captured)
+	}
+	if errx.StackOf(errx.New("fail").WithStack()) == nil {
+		t.Error("explicit WithStack should never be sampled")
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	t.Parallel()
+
+	frames := errx.StackOf(errx.New("fail").WithCaller()).Frames()
+	if len(frames) != 1 {
+		t.Fatalf("frames = %d, want 1", len(frames))
+	}
+	if !strings.Contains(frames[0].Function, "TestWithCaller") {
+		t.Errorf("frame function = %q, want containing %q", frames[0].Function, "TestWithCaller")
+	}
+	if !strings.HasSuffix(frames[0].File, "stack_test.go") || frames[0].Line == 0 {
+		t.Errorf("frame = %+v, want a line in stack_test.go", frames[0])
+	}
+}
+
+func TestStack_Frames_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	s := errx.StackOf(errx.New("fail").WithStack())
+	want := s.Frames()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			if got := s.Frames(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Frames() = %v, want %v", got, want)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkWithStack(b *testing.B) {
+	err := errx.New("fail")
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = err.WithStack()
+	}
+}
+
+func BenchmarkWithStack_Frames(b *testing.B) {
+	err := errx.New("fail")
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = errx.StackOf(err.WithStack()).Frames()
+	}
+}
+
+func BenchmarkWithCaller(b *testing.B) {
+	err := errx.New("fail")
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = err.WithCaller()
+	}
+}
+
+func TestStacksOf(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan error)
+	go func() { ch <- errx.New("worker failed").WithStack() }()
+	inner := <-ch
+	outer := errx.Wrap(inner, "job", 1).WithStack()
+
+	stacks := errx.StacksOf(outer)
+	if len(stacks) != 2 {
+		t.Fatalf("StacksOf length = %d, want 2", len(stacks))
+	}
+	if stacks[0] != errx.StackOf(outer) {
+		t.Error("StacksOf should list the outermost stack first")
+	}
+	if !strings.Contains(stacks[1].Frames()[0].Function, "TestStacksOf.func") {
+		t.Errorf("inner stack top = %q, want the goroutine", stacks[1].Frames()[0].Function)
+	}
+	if errx.StacksOf(errx.New("no stack")) != nil {
+		t.Error("StacksOf should return nil when no stack is captured")
+	}
+}
+
+func TestStack_Trim(t *testing.T) {
+	t.Parallel()
+
+	inner := newStackError()
+	outer := errx.Wrap(inner).WithStack()
+
+	innerFrames := errx.StackOf(inner).Frames()
+	trimmed := errx.StackOf(inner).Trim(errx.StackOf(outer))
+	if len(trimmed) == 0 || len(trimmed) >= len(innerFrames) {
+		t.Fatalf("trimmed = %d frames, want between 1 and %d", len(trimmed), len(innerFrames)-1)
+	}
+	if last := trimmed[len(trimmed)-1]; !strings.Contains(last.Function, "TestStack_Trim") {
+		t.Errorf("last kept frame = %q, want the call site in TestStack_Trim", last.Function)
+	}
+	if got := errx.StackOf(inner).Trim(nil); !reflect.DeepEqual(got, innerFrames) {
+		t.Errorf("Trim(nil) = %v, want all frames", got)
+	}
+}
+
+//go:noinline
+func newStackError() *errx.Error {
+	return errx.New("inner").WithStack()
+}
@@ -0,0 +1,148 @@
+package errx
+
+import (
+	"maps"
+	"strings"
+)
+
+// compile-time checks
+var _ Err = (*Template)(nil)
+
+// Template declares an error once — its code, message pattern, default details
+// and ErrorInfo reason — so that every error of that kind is worded the same way.
+//
+//	var ErrUserNotFound = errx.Define(errx.NotFound, "user {user_id} not found",
+//	    errx.WithReason("USER_NOT_FOUND", "example.com"),
+//	)
+//
+//	err := ErrUserNotFound.New("user_id", 42) // "user 42 not found"
+//	errors.Is(err, ErrUserNotFound)           // true
+type Template struct {
+	code    Code
+	pattern string
+	details []any
+	reason  string
+	domain  string
+}
+
+// TemplateOption configures a [Template] created by [Define].
+type TemplateOption func(*Template)
+
+// WithDefaultDetails attaches the given details to every error produced by the template.
+func WithDefaultDetails(details ...any) TemplateOption {
+	return func(t *Template) {
+		t.details = append(t.details, details...)
+	}
+}
+
+// WithReason attaches an [ErrorInfo] detail with the given reason and domain
+// to every error produced by the template. Its metadata holds the error's fields
+// in their string form.
+func WithReason(reason, domain string) TemplateOption {
+	return func(t *Template) {
+		t.reason = reason
+		t.domain = domain
+	}
+}
+
+// Define declares an error template with the given code and message pattern.
+// Placeholders of the form {key} in the pattern are replaced by the value of the
+// field with that key (redacted for secrets and sensitive keys);
+// placeholders without a matching field are kept as-is.
+func Define(code Code, pattern string, opts ...TemplateOption) *Template {
+	t := &Template{code: code, pattern: pattern}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// New creates an Error from the template.
+// Fields follow the same convention as [New] and are kept with their types.
+func (t *Template) New(args ...any) *Error {
+	e := t.build(nil, args)
+	e.stack = autoStack(nil, t.code)
+	return e
+}
+
+// Wrap creates an Error from the template that wraps err.
+// Returns nil if err is nil.
+func (t *Template) Wrap(err error, args ...any) *Error {
+	if err == nil {
+		return nil
+	}
+	e := t.build(err, args)
+	e.stack = autoStack(err, t.code)
+	return e
+}
+
+// Error implements the error interface, returning the message pattern.
+// It allows the template to be used as the target of errors.Is.
+func (t *Template) Error() string { return t.pattern }
+
+// Code returns the code of errors produced by the template.
+func (t *Template) Code() Code { return t.code }
+
+func (t *Template) build(cause error, args []any) *Error {
+	fields := argsToAttrs(args)
+	values := make(map[string]string, len(fields))
+	var secrets map[string]string
+	for _, a := range fields {
+		v := redactAttr(a).Value.Resolve().String()
+		values[a.Key] = v
+		if revealed := revealAttr(a).Value.Resolve().String(); revealed != v {
+			if secrets == nil {
+				secrets = maps.Clone(values)
+			}
+			secrets[a.Key] = revealed
+		} else if secrets != nil {
+			secrets[a.Key] = v
+		}
+	}
+
+	e := &Error{
+		msg:    interpolate(t.pattern, values),
+		cause:  cause,
+		code:   t.code,
+		fields: fields,
+		tmpl:   t,
+	}
+	if secrets != nil {
+		e.secretMsg = interpolate(t.pattern, secrets)
+	}
+	e.details = append(e.details, t.details...)
+	if t.reason != "" {
+		e.details = append(e.details, ErrorInfo(t.reason, t.domain, maps.Clone(values)))
+	}
+	return e
+}
+
+// interpolate replaces every {key} in pattern with values[key].
+// Placeholders without a value are kept as-is.
+func interpolate(pattern string, values map[string]string) string {
+	if !strings.Contains(pattern, "{") {
+		return pattern
+	}
+	var b strings.Builder
+	rest := pattern
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			break
+		}
+		end += open
+		b.WriteString(rest[:open])
+		if v, ok := values[rest[open+1:end]]; ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(rest[open : end+1])
+		}
+		rest = rest[end+1:]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package errx_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/mickamy/errx"
+)
+
+func TestTemplate_New(t *testing.T) {
+	t.Parallel()
+
+	tmpl := errx.Define(errx.NotFound, "user {user_id} not found in {tenant}",
+		errx.WithDefaultDetails(errx.ResourceInfo("User", "", "", "not found")),
+		errx.WithReason("USER_NOT_FOUND", "example.com"),
+	)
+
+	err := tmpl.New("user_id", 42, "tenant", "acme")
+	if err.Error() != "user 42 not found in acme" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "user 42 not found in acme")
+	}
+	if err.Code() != errx.NotFound {
+		t.Errorf("Code() = %q, want %q", err.Code(), errx.NotFound)
+	}
+
+	fields := errx.Fields(err)
+	if len(fields) != 2 || fields[0].Value.Kind() != slog.KindInt64 {
+		t.Errorf("fields = %v, want typed user_id and tenant", fields)
+	}
+
+	details := errx.DetailsOf(err)
+	if len(details) != 2 {
+		t.Fatalf("details length = %d, want 2", len(details))
+	}
+	if _, ok := details[0].(*errx.ResourceInfoDetail); !ok {
+		t.Errorf("details[0] = %T, want *errx.ResourceInfoDetail", details[0])
+	}
+	info, ok := details[1].(*errx.ErrorInfoDetail)
+	if !ok {
+		t.Fatalf("details[1] = %T, want *errx.ErrorInfoDetail", details[1])
+	}
+	if info.Reason != "USER_NOT_FOUND" || info.Domain != "example.com" || info.Metadata["user_id"] != "42" {
+		t.Errorf("ErrorInfo = %+v", info)
+	}
+}
+
+func TestTemplate_Interpolation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+		args    []any
+		want    string
+	}{
+		{"no placeholders", "not found", []any{"id", 1}, "not found"},
+		{"missing field kept", "user {user_id} not found", nil, "user {user_id} not found"},
+		{"slog attr", "took {elapsed}", []any{slog.String("elapsed", "3s")}, "took 3s"},
+		{"unterminated", "bad {brace", []any{"brace", "x"}, "bad {brace"},
+		{"repeated", "{a}-{a}", []any{"a", "x"}, "x-x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := errx.Define(errx.Internal, tt.pattern).New(tt.args...).Error()
+			if got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate_ErrorsIs(t *testing.T) {
+	t.Parallel()
+
+	tmpl := errx.Define(errx.Unavailable, "{service} unavailable")
+	other := errx.Define(errx.Unavailable, "{service} unavailable")
+	cause := errors.New("connection refused")
+
+	wrapped := tmpl.Wrap(cause, "service", "db")
+	if wrapped.Error() != "db unavailable: connection refused" {
+		t.Errorf("Error() = %q", wrapped.Error())
+	}
+	if !errors.Is(errx.Wrap(wrapped, "attempt", 3), tmpl) {
+		t.Error("errors.Is should match the template through wrappers")
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Error("errors.Is should still match the cause")
+	}
+	if errors.Is(wrapped, other) {
+		t.Error("errors.Is should not match another template")
+	}
+	if errors.Is(errx.New("db unavailable"), tmpl) {
+		t.Error("errors.Is should not match errors not produced by the template")
+	}
+	if tmpl.Wrap(nil) != nil {
+		t.Error("Wrap(nil) should return nil")
+	}
+	if errx.CodeOf(tmpl) != errx.Unavailable {
+		t.Errorf("CodeOf(tmpl) = %q, want %q", errx.CodeOf(tmpl), errx.Unavailable)
+	}
+}